@@ -10,6 +10,7 @@ import (
 
 	"github.com/mitchellh/cli"
 	"github.com/mitchellh/colorstring"
+	"github.com/openebs/mayaserver/lib/api"
 )
 
 const (
@@ -36,6 +37,10 @@ const (
 type Meta struct {
 	Ui cli.Ui
 
+	// These are set by the command line flags
+	flagAddress string
+	token       string
+
 	// Whether to not-colorize output
 	noColor bool
 }
@@ -50,6 +55,8 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 	// FlagSetClient is used to enable the settings for specifying
 	// client connectivity options.
 	if fs&FlagSetClient != 0 {
+		f.StringVar(&m.flagAddress, "address", "", "")
+		f.StringVar(&m.token, "token", "", "")
 		f.BoolVar(&m.noColor, "no-color", false, "")
 	}
 
@@ -69,6 +76,19 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 	return f
 }
 
+// Client returns a client of maya api server as per the -address & -token
+// flags. The MAPI_ADDR & MAPI_TOKEN env vars apply if the flags are not set.
+func (m *Meta) Client() (*api.Client, error) {
+	config := api.DefaultConfig()
+	if m.flagAddress != "" {
+		config.Address = m.flagAddress
+	}
+	if m.token != "" {
+		config.Token = m.token
+	}
+	return api.NewClient(config)
+}
+
 // Colorize returns all the including fields.
 func (m *Meta) Colorize() *colorstring.Colorize {
 	return &colorstring.Colorize{
@@ -78,9 +98,25 @@ func (m *Meta) Colorize() *colorstring.Colorize {
 	}
 }
 
+// boldHeader highlights the first line of a table
+func (m *Meta) boldHeader(table string) string {
+	lines := strings.SplitN(table, "\n", 2)
+	lines[0] = m.Colorize().Color("[bold]" + lines[0])
+	return strings.Join(lines, "\n")
+}
+
 // generalOptionsUsage returns the help string for the global options.
 func generalOptionsUsage() string {
 	helpText := `
+  -address=<addr>
+    The address of maya api server.
+    Overrides the MAPI_ADDR environment variable if set.
+    Default = http://127.0.0.1:5656
+
+  -token=<token>
+    The management token that is sent to maya api server.
+    Overrides the MAPI_TOKEN environment variable if set.
+
   -no-color
     Disables colored command output.
 `
@@ -19,7 +19,9 @@ func TestMeta_FlagSet(t *testing.T) {
 		{
 			FlagSetClient,
 			[]string{
+				"address",
 				"no-color",
+				"token",
 			},
 		},
 	}
@@ -172,7 +172,7 @@ func (c *UpCommand) setupLoggers(mconfig *config.MayaConfig) (*loghelper.Writer,
 }
 
 // setupMayaServer is used to start Maya server
-func (c *UpCommand) setupMayaServer(mconfig *config.MayaConfig, logOutput io.Writer) error {
+func (c *UpCommand) setupMayaServer(mconfig *config.MayaConfig, logOutput io.Writer, logRegistrar *loghelper.LogRegistrar) error {
 	c.Ui.Output("Starting maya api server ...")
 
 	// Setup maya service i.e. maya api server
@@ -184,6 +184,11 @@ func (c *UpCommand) setupMayaServer(mconfig *config.MayaConfig, logOutput io.Wri
 
 	c.maya = maya
 
+	// Let the api server stream the logs & change the log level. These are
+	// set before the HTTP server starts serving the requests.
+	maya.SetLogRegistrar(logRegistrar)
+	maya.SetLogFilter(c.logFilter)
+
 	// Setup the HTTP server
 	http, err := server.NewHTTPServer(maya, mconfig, logOutput)
 	if err != nil {
@@ -214,7 +219,7 @@ func (c *UpCommand) Run(args []string) int {
 	}
 
 	// Setup the log outputs
-	logGate, _, logOutput := c.setupLoggers(mconfig)
+	logGate, logRegistrar, logOutput := c.setupLoggers(mconfig)
 	if logGate == nil {
 		return 1
 	}
@@ -227,7 +232,7 @@ func (c *UpCommand) Run(args []string) int {
 	}
 
 	// Setup Maya server
-	if err := c.setupMayaServer(mconfig, logOutput); err != nil {
+	if err := c.setupMayaServer(mconfig, logOutput, logRegistrar); err != nil {
 		return 1
 	}
 	defer c.maya.Shutdown()
@@ -354,14 +359,10 @@ func (c *UpCommand) handleReload(mconfig *config.MayaConfig) *config.MayaConfig
 		return mconfig
 	}
 
-	// Change the log level
-	minLevel := logutils.LogLevel(strings.ToUpper(newConf.LogLevel))
-	if loghelper.ValidateLevelFilter(minLevel, c.logFilter) {
-		c.logFilter.SetMinLevel(minLevel)
-	} else {
-		c.Ui.Error(fmt.Sprintf(
-			"Invalid log level: %s. Valid log levels are: %v",
-			minLevel, c.logFilter.Levels))
+	// Change the log level. This cancels any temporary change of the level
+	// done via the API.
+	if err := c.maya.ReloadLogLevel(newConf.LogLevel); err != nil {
+		c.Ui.Error(err.Error())
 
 		// Keep the current log level
 		newConf.LogLevel = mconfig.LogLevel
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+	"github.com/mitchellh/cli"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+// VolumeCommand is a cli implementation that groups the VSM subcommands
+type VolumeCommand struct {
+	Meta
+}
+
+// Help returns the usage of the volume subcommands
+func (c *VolumeCommand) Help() string {
+	helpText := `
+Usage: m-apiserver volume <subcommand> [options] [args]
+
+  Interacts with the VSMs of a running maya api server. The subcommands
+  talk to the server over its HTTP API.
+
+  Create a VSM out of a claim:
+
+      $ m-apiserver volume create -f pvc.yaml
+
+  List the VSMs:
+
+      $ m-apiserver volume list
+
+  Describe a VSM:
+
+      $ m-apiserver volume info <name>
+
+  Delete a VSM:
+
+      $ m-apiserver volume delete <name>
+
+  Please see the individual subcommand help for detailed usage information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis returns the summary of the volume subcommands
+func (c *VolumeCommand) Synopsis() string {
+	return "Interact with the VSMs"
+}
+
+// Run shows the help since volume needs a subcommand
+func (c *VolumeCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// outputFormatUsage returns the help string for the output options
+func outputFormatUsage() string {
+	helpText := `
+  -json
+    Outputs the VSM(s) in JSON format.
+
+  -yaml
+    Outputs the VSM(s) in YAML format.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// formatObject encodes the object as JSON or YAML
+func formatObject(obj interface{}, asJSON, asYAML bool) (string, error) {
+	if asJSON && asYAML {
+		return "", fmt.Errorf("-json and -yaml can not be used together")
+	}
+
+	b, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if asYAML {
+		if b, err = yaml.JSONToYAML(b); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// formatVSMList formats the VSMs as a table
+func formatVSMList(l *mapiv1.VSMList) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Name\tCapacity\tReplicas\tHealth\tPhase")
+	for _, vsm := range l.Items {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+			vsm.Name, orDash(vsm.Spec.Capacity), vsm.Spec.ReplicaCount, orDash(string(vsm.Status.Health)), orDash(string(vsm.Status.Phase)))
+	}
+	w.Flush()
+
+	return strings.TrimSpace(buf.String())
+}
+
+// formatVSM formats a single VSM as key value pairs followed by its
+// controllers, replicas & conditions
+func formatVSM(vsm *mapiv1.VSM) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name\t= %s\n", vsm.Name)
+	fmt.Fprintf(w, "Capacity\t= %s\n", orDash(vsm.Spec.Capacity))
+	fmt.Fprintf(w, "Replicas\t= %d\n", vsm.Spec.ReplicaCount)
+	fmt.Fprintf(w, "Health\t= %s\n", orDash(string(vsm.Status.Health)))
+	fmt.Fprintf(w, "Phase\t= %s\n", orDash(string(vsm.Status.Phase)))
+	fmt.Fprintf(w, "IQN\t= %s\n", orDash(vsm.Status.Target.IQN))
+	fmt.Fprintf(w, "Portals\t= %s\n", orDash(strings.Join(vsm.Status.Target.Portals, ", ")))
+	w.Flush()
+
+	if len(vsm.Status.Controllers) > 0 {
+		buf.WriteString("\nControllers\n")
+		w = tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "IP\tStatus")
+		for _, ctrl := range vsm.Status.Controllers {
+			fmt.Fprintf(w, "%s\t%s\n", orDash(ctrl.IP), orDash(ctrl.Status))
+		}
+		w.Flush()
+	}
+
+	if len(vsm.Status.Replicas) > 0 {
+		buf.WriteString("\nReplicas\n")
+		w = tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "IP\tStatus\tNode")
+		for _, rep := range vsm.Status.Replicas {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", orDash(rep.IP), orDash(rep.Status), orDash(rep.Node))
+		}
+		w.Flush()
+	}
+
+	if len(vsm.Status.Conditions) > 0 {
+		buf.WriteString("\nConditions\n")
+		w = tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "Type\tStatus\tReason")
+		for _, cond := range vsm.Status.Conditions {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", cond.Type, cond.Status, orDash(cond.Reason))
+		}
+		w.Flush()
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// orDash returns a dash for an empty value
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	mayav1 "github.com/openebs/maya/types/v1"
+	"github.com/openebs/mayaserver/lib/server"
+)
+
+// VolumeCreateCommand is a cli implementation that creates a VSM out of a
+// claim
+type VolumeCreateCommand struct {
+	Meta
+}
+
+// Help returns the usage of volume create
+func (c *VolumeCreateCommand) Help() string {
+	helpText := `
+Usage: m-apiserver volume create [options] -f <path>
+
+  Creates a VSM out of a persistent volume claim. The claim is read from a
+  YAML or JSON file, or from stdin if the path is "-".
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Create Options:
+
+  -f=<path>
+    The path of the claim.
+
+  ` + outputFormatUsage()
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis returns the summary of volume create
+func (c *VolumeCreateCommand) Synopsis() string {
+	return "Create a VSM out of a claim"
+}
+
+// Run creates the VSM
+func (c *VolumeCreateCommand) Run(args []string) int {
+	var path string
+	var asJSON, asYAML bool
+
+	flags := c.Meta.FlagSet("volume create", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&path, "f", "", "")
+	flags.BoolVar(&asJSON, "json", false, "")
+	flags.BoolVar(&asYAML, "yaml", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if path == "" || len(flags.Args()) != 0 {
+		c.Ui.Error("This command takes the path of the claim via -f")
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	pvc, err := readClaim(path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading claim: %s", err))
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	vsm, err := client.Volumes().Create(pvc, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error creating VSM: %s", err))
+		return 1
+	}
+
+	if asJSON || asYAML {
+		out, err := formatObject(vsm, asJSON, asYAML)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error formatting VSM: %s", err))
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[green]VSM %q is created", vsm.Name)))
+	c.Ui.Output(formatVSM(vsm))
+	return 0
+}
+
+// readClaim decodes the claim from a YAML or JSON file. "-" reads the claim
+// from stdin. A claim with a field that is not known is rejected, as a
+// misspelt field would otherwise be dropped silently.
+func readClaim(path string) (*mayav1.PersistentVolumeClaim, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pvc := &mayav1.PersistentVolumeClaim{}
+	if err := yaml.Unmarshal(b, pvc); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	if err := server.CheckUnknownYamlFields(b, pvc); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	if pvc.Name == "" {
+		return nil, fmt.Errorf("claim in %s has no name", path)
+	}
+	return pvc, nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openebs/mayaserver/lib/api"
+)
+
+// VolumeDeleteCommand is a cli implementation that deletes a VSM
+type VolumeDeleteCommand struct {
+	Meta
+}
+
+// Help returns the usage of volume delete
+func (c *VolumeDeleteCommand) Help() string {
+	helpText := `
+Usage: m-apiserver volume delete [options] <name>
+
+  Deletes a VSM along with its controllers & replicas.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis returns the summary of volume delete
+func (c *VolumeDeleteCommand) Synopsis() string {
+	return "Delete a VSM"
+}
+
+// Run deletes the VSM
+func (c *VolumeDeleteCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("volume delete", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <name>")
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name := args[0]
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	err = client.Volumes().Delete(name, nil)
+	if api.IsNotFound(err) {
+		c.Ui.Error(fmt.Sprintf("No VSM with name %q found", name))
+		return 1
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error deleting VSM: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[green]VSM %q is deleted", name)))
+	return 0
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openebs/mayaserver/lib/api"
+)
+
+// VolumeInfoCommand is a cli implementation that describes a VSM
+type VolumeInfoCommand struct {
+	Meta
+}
+
+// Help returns the usage of volume info
+func (c *VolumeInfoCommand) Help() string {
+	helpText := `
+Usage: m-apiserver volume info [options] <name>
+
+  Describes a VSM along with its controllers, replicas & conditions.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Info Options:
+
+  ` + outputFormatUsage()
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis returns the summary of volume info
+func (c *VolumeInfoCommand) Synopsis() string {
+	return "Describe a VSM"
+}
+
+// Run describes the VSM
+func (c *VolumeInfoCommand) Run(args []string) int {
+	var asJSON, asYAML bool
+
+	flags := c.Meta.FlagSet("volume info", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&asJSON, "json", false, "")
+	flags.BoolVar(&asYAML, "yaml", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("This command takes one argument: <name>")
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name := args[0]
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	vsm, _, err := client.Volumes().Info(name, nil)
+	if api.IsNotFound(err) {
+		c.Ui.Error(fmt.Sprintf("No VSM with name %q found", name))
+		return 1
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading VSM: %s", err))
+		return 1
+	}
+
+	if asJSON || asYAML {
+		out, err := formatObject(vsm, asJSON, asYAML)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error formatting VSM: %s", err))
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+
+	c.Ui.Output(formatVSM(vsm))
+	return 0
+}
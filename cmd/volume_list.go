@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VolumeListCommand is a cli implementation that lists the VSMs
+type VolumeListCommand struct {
+	Meta
+}
+
+// Help returns the usage of volume list
+func (c *VolumeListCommand) Help() string {
+	helpText := `
+Usage: m-apiserver volume list [options]
+
+  Lists the VSMs of maya api server.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+List Options:
+
+  ` + outputFormatUsage()
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis returns the summary of volume list
+func (c *VolumeListCommand) Synopsis() string {
+	return "List the VSMs"
+}
+
+// Run lists the VSMs
+func (c *VolumeListCommand) Run(args []string) int {
+	var asJSON, asYAML bool
+
+	flags := c.Meta.FlagSet("volume list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&asJSON, "json", false, "")
+	flags.BoolVar(&asYAML, "yaml", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(flags.Args()) != 0 {
+		c.Ui.Error("This command takes no arguments")
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	l, _, err := client.Volumes().List(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error listing VSMs: %s", err))
+		return 1
+	}
+
+	if asJSON || asYAML {
+		out, err := formatObject(l, asJSON, asYAML)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error formatting VSMs: %s", err))
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+
+	if len(l.Items) == 0 {
+		c.Ui.Output("No VSMs found")
+		return 0
+	}
+
+	c.Ui.Output(c.Meta.boldHeader(formatVSMList(l)))
+	return 0
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	mayav1 "github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/server"
+)
+
+func TestVolumeCommand_Implements(t *testing.T) {
+	var _ cli.Command = &VolumeCommand{}
+	var _ cli.Command = &VolumeCreateCommand{}
+	var _ cli.Command = &VolumeListCommand{}
+	var _ cli.Command = &VolumeInfoCommand{}
+	var _ cli.Command = &VolumeDeleteCommand{}
+}
+
+// fakeVSMServer serves a fixed VSM over the typed VSM endpoints
+func fakeVSMServer(t *testing.T) *httptest.Server {
+	vsm := mapiv1.VSM{}
+	vsm.Kind = mapiv1.VSMKind
+	vsm.Name = "vol1"
+	vsm.Spec.Capacity = "5G"
+	vsm.Spec.ReplicaCount = 2
+	vsm.Status.Health = mapiv1.Healthy
+	vsm.Status.Target.IQN = "iqn.2016-09.com.openebs.jiva:vol1"
+	vsm.Status.Replicas = []mapiv1.Replica{{IP: "10.0.0.2", Status: "Running", Node: "node-a"}}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v2/volumes/":
+			json.NewEncoder(w).Encode(&mapiv1.VSMList{Items: []mapiv1.VSM{vsm}})
+		case r.Method == "GET" && r.URL.Path == "/v2/volumes/vol1":
+			json.NewEncoder(w).Encode(&vsm)
+		case r.Method == "DELETE" && r.URL.Path == "/v2/volumes/vol1":
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+			w.Write([]byte("VSM not found"))
+		}
+	}))
+}
+
+func TestVolumeListCommand(t *testing.T) {
+	ts := fakeVSMServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{}, "vol1  5G        2         Healthy"},
+		{[]string{"-json"}, `"name": "vol1"`},
+		{[]string{"-yaml"}, "name: vol1"},
+	}
+	for _, c := range cases {
+		ui := new(cli.MockUi)
+		cmd := &VolumeListCommand{Meta: Meta{Ui: ui}}
+
+		args := append([]string{"-address=" + ts.URL, "-no-color"}, c.args...)
+		if code := cmd.Run(args); code != 0 {
+			t.Fatalf("ERR: %v expected exit 0, got %d: %s", c.args, code, ui.ErrorWriter.String())
+		}
+		if out := ui.OutputWriter.String(); !strings.Contains(out, c.expected) {
+			t.Fatalf("ERR: %v expected %q in the output: %s", c.args, c.expected, out)
+		}
+	}
+
+	// -json & -yaml are exclusive
+	ui := new(cli.MockUi)
+	cmd := &VolumeListCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + ts.URL, "-json", "-yaml"}); code != 1 {
+		t.Fatalf("ERR: expected exit 1, got %d", code)
+	}
+}
+
+func TestVolumeInfoCommand(t *testing.T) {
+	ts := fakeVSMServer(t)
+	defer ts.Close()
+
+	ui := new(cli.MockUi)
+	cmd := &VolumeInfoCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + ts.URL, "vol1"}); code != 0 {
+		t.Fatalf("ERR: expected exit 0, got %d: %s", code, ui.ErrorWriter.String())
+	}
+	out := ui.OutputWriter.String()
+	for _, expected := range []string{"iqn.2016-09.com.openebs.jiva:vol1", "Replicas", "node-a"} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("ERR: expected %q in the output: %s", expected, out)
+		}
+	}
+
+	// An unknown VSM
+	ui = new(cli.MockUi)
+	cmd = &VolumeInfoCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + ts.URL, "vol2"}); code != 1 {
+		t.Fatalf("ERR: expected exit 1, got %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "No VSM with name") {
+		t.Fatalf("ERR: unexpected error: %s", ui.ErrorWriter.String())
+	}
+
+	// The name is required
+	ui = new(cli.MockUi)
+	cmd = &VolumeInfoCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + ts.URL}); code != 1 {
+		t.Fatalf("ERR: expected exit 1, got %d", code)
+	}
+}
+
+func TestVolumeDeleteCommand(t *testing.T) {
+	ts := fakeVSMServer(t)
+	defer ts.Close()
+
+	ui := new(cli.MockUi)
+	cmd := &VolumeDeleteCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + ts.URL, "-no-color", "vol1"}); code != 0 {
+		t.Fatalf("ERR: expected exit 0, got %d: %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, `VSM "vol1" is deleted`) {
+		t.Fatalf("ERR: unexpected output: %s", out)
+	}
+}
+
+func TestVolumeCreateCommand(t *testing.T) {
+	s := server.NewTestServer(t, nil)
+	defer s.Cleanup()
+
+	dir, err := ioutil.TempDir("", "mayaserver")
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The claim is rejected by the server before the orchestrator is
+	// invoked
+	path := filepath.Join(dir, "pvc.yaml")
+	claim := `
+kind: PersistentVolumeClaim
+apiVersion: v1
+metadata:
+  name: vol1
+  labels:
+    ` + string(mayav1.PVPReplicaCountLbl) + `: "zero"
+`
+	if err := ioutil.WriteFile(path, []byte(claim), 0600); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	ui := new(cli.MockUi)
+	cmd := &VolumeCreateCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + s.HTTPAddr, "-f", path}); code != 1 {
+		t.Fatalf("ERR: expected exit 1, got %d", code)
+	}
+	if errOut := ui.ErrorWriter.String(); !strings.Contains(errOut, "422") || !strings.Contains(errOut, string(mayav1.PVPReplicaCountLbl)) {
+		t.Fatalf("ERR: expected the validation error, got: %s", errOut)
+	}
+
+	// The path is required
+	ui = new(cli.MockUi)
+	cmd = &VolumeCreateCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + s.HTTPAddr}); code != 1 {
+		t.Fatalf("ERR: expected exit 1, got %d", code)
+	}
+
+	// A claim with a misspelt field
+	if err := ioutil.WriteFile(path, []byte("kind: PersistentVolumeClaim\nmetadata:\n  name: vol1\n  lables: {}\n"), 0600); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	ui = new(cli.MockUi)
+	cmd = &VolumeCreateCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + s.HTTPAddr, "-f", path}); code != 1 {
+		t.Fatalf("ERR: expected exit 1, got %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "'metadata.lables'") {
+		t.Fatalf("ERR: expected the unknown field error, got: %s", ui.ErrorWriter.String())
+	}
+
+	// A claim without a name
+	if err := ioutil.WriteFile(path, []byte("kind: PersistentVolumeClaim\n"), 0600); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	ui = new(cli.MockUi)
+	cmd = &VolumeCreateCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + s.HTTPAddr, "-f", path}); code != 1 {
+		t.Fatalf("ERR: expected exit 1, got %d", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "has no name") {
+		t.Fatalf("ERR: unexpected error: %s", ui.ErrorWriter.String())
+	}
+}
@@ -33,6 +33,31 @@ func Commands(metaPtr *cmd.Meta) map[string]cli.CommandFactory {
 				ShutdownCh:        make(chan struct{}),
 			}, nil
 		},
+		"volume": func() (cli.Command, error) {
+			return &cmd.VolumeCommand{
+				Meta: meta,
+			}, nil
+		},
+		"volume create": func() (cli.Command, error) {
+			return &cmd.VolumeCreateCommand{
+				Meta: meta,
+			}, nil
+		},
+		"volume delete": func() (cli.Command, error) {
+			return &cmd.VolumeDeleteCommand{
+				Meta: meta,
+			}, nil
+		},
+		"volume info": func() (cli.Command, error) {
+			return &cmd.VolumeInfoCommand{
+				Meta: meta,
+			}, nil
+		},
+		"volume list": func() (cli.Command, error) {
+			return &cmd.VolumeListCommand{
+				Meta: meta,
+			}, nil
+		},
 		"version": func() (cli.Command, error) {
 			ver := Version
 			rel := VersionPrerelease
@@ -0,0 +1,370 @@
+// Package admission runs a chain of policies on a claim before its VSM is
+// provisioned. Mutating policies run first & may set the labels of the
+// claim. Validating policies run next & may deny the claim.
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openebs/maya/types/v1"
+)
+
+const (
+	// Mutating policies may set the labels of a claim
+	Mutating = "mutating"
+
+	// Validating policies may deny a claim
+	Validating = "validating"
+)
+
+const (
+	// FailurePolicyFail fails the claim if an admission webhook can not be
+	// reached
+	FailurePolicyFail = "fail"
+
+	// FailurePolicyIgnore skips an admission webhook that can not be
+	// reached
+	FailurePolicyIgnore = "ignore"
+)
+
+// DefaultWebhookTimeout is the timeout of a call to an admission webhook if
+// the policy does not specify one
+const DefaultWebhookTimeout = 10 * time.Second
+
+// Mutator is a policy that mutates a claim
+type Mutator interface {
+	Name() string
+	Mutate(pvc *v1.PersistentVolumeClaim) error
+}
+
+// Validator is a policy that validates a claim
+type Validator interface {
+	Name() string
+	Validate(pvc *v1.PersistentVolumeClaim) error
+}
+
+// DeniedError is the error of a policy that denies a claim
+type DeniedError struct {
+	Policy string
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("Admission policy '%s' denied the claim: %s", e.Policy, e.Reason)
+}
+
+// IsDenied returns true if the error is the denial of a claim
+func IsDenied(err error) bool {
+	_, ok := err.(*DeniedError)
+	return ok
+}
+
+// Chain runs the mutating & the validating policies in order
+type Chain struct {
+	Mutators   []Mutator
+	Validators []Validator
+}
+
+// Mutate runs the mutating policies on the claim. It stops at the first
+// policy that fails.
+func (c *Chain) Mutate(pvc *v1.PersistentVolumeClaim) error {
+	if c == nil {
+		return nil
+	}
+	for _, m := range c.Mutators {
+		if err := m.Mutate(pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate runs the validating policies on the claim. It stops at the first
+// policy that denies the claim or fails.
+func (c *Chain) Validate(pvc *v1.PersistentVolumeClaim) error {
+	if c == nil {
+		return nil
+	}
+	for _, v := range c.Validators {
+		if err := v.Validate(pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Selector selects the claims a policy applies to by the glob patterns of
+// their namespace & their name e.g. db-*. A policy without patterns applies
+// to every claim.
+type Selector struct {
+	Namespaces []string
+	Names      []string
+}
+
+// Validate returns an error if a pattern is malformed
+func (s Selector) Validate() error {
+	for _, p := range append(append([]string{}, s.Namespaces...), s.Names...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %v", p, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the policy applies to the claim
+func (s Selector) Matches(pvc *v1.PersistentVolumeClaim) bool {
+	return matchesAny(s.Namespaces, Namespace(pvc)) && matchesAny(s.Names, pvc.Name)
+}
+
+// matchesAny returns true if there are no patterns or if any of them
+// matches the value
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Namespace returns the namespace the VSM of the claim is provisioned in
+func Namespace(pvc *v1.PersistentVolumeClaim) string {
+	return v1.GetOrchestratorNS(pvc.Labels)
+}
+
+// LabelPolicy is a mutating policy that sets default labels on the claims
+// it selects e.g. the replica image
+type LabelPolicy struct {
+	PolicyName string
+	Selector   Selector
+	Labels     map[string]string
+
+	// Overwrite replaces the labels that are set by the claim. Otherwise
+	// only the missing labels are set.
+	Overwrite bool
+}
+
+// Name returns the name of the policy
+func (p *LabelPolicy) Name() string {
+	return p.PolicyName
+}
+
+// Mutate sets the labels of the policy on the claim
+func (p *LabelPolicy) Mutate(pvc *v1.PersistentVolumeClaim) error {
+	if !p.Selector.Matches(pvc) {
+		return nil
+	}
+
+	setLabels(pvc, p.Labels, p.Overwrite)
+	return nil
+}
+
+// setLabels sets the labels on the claim
+func setLabels(pvc *v1.PersistentVolumeClaim, labels map[string]string, overwrite bool) {
+	if len(labels) == 0 {
+		return
+	}
+	if pvc.Labels == nil {
+		pvc.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		if _, ok := pvc.Labels[k]; ok && !overwrite {
+			continue
+		}
+		pvc.Labels[k] = v
+	}
+}
+
+// RulePolicy is a validating policy that denies the claims it selects if
+// they set a forbidden label value or miss a required label
+type RulePolicy struct {
+	PolicyName string
+	Selector   Selector
+
+	// Forbid are the forbidden values of the labels e.g. a replica count
+	// of 1
+	Forbid map[string][]string
+
+	// Require are the labels that must be set
+	Require []string
+
+	// Message is the reason of a denial. A reason is derived from the
+	// violated rule if it is not set.
+	Message string
+}
+
+// Name returns the name of the policy
+func (p *RulePolicy) Name() string {
+	return p.PolicyName
+}
+
+// Validate denies the claim if it violates a rule of the policy
+func (p *RulePolicy) Validate(pvc *v1.PersistentVolumeClaim) error {
+	if !p.Selector.Matches(pvc) {
+		return nil
+	}
+
+	if reason := p.violation(pvc); reason != "" {
+		if p.Message != "" {
+			reason = p.Message
+		}
+		return &DeniedError{Policy: p.PolicyName, Reason: reason}
+	}
+	return nil
+}
+
+// violation returns the first rule violated by the claim
+func (p *RulePolicy) violation(pvc *v1.PersistentVolumeClaim) string {
+	for _, k := range p.Require {
+		if strings.TrimSpace(pvc.Labels[k]) == "" {
+			return fmt.Sprintf("label '%s' is required", k)
+		}
+	}
+
+	// Sorted for a deterministic reason
+	keys := make([]string, 0, len(p.Forbid))
+	for k := range p.Forbid {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		value, ok := pvc.Labels[k]
+		if !ok {
+			continue
+		}
+		for _, forbidden := range p.Forbid[k] {
+			if strings.TrimSpace(value) == forbidden {
+				return fmt.Sprintf("label '%s' can not be '%s'", k, forbidden)
+			}
+		}
+	}
+	return ""
+}
+
+// Review is the payload posted to an admission webhook
+type Review struct {
+	// Type is either mutating or validating
+	Type      string                    `json:"type"`
+	Namespace string                    `json:"namespace"`
+	Claim     *v1.PersistentVolumeClaim `json:"claim"`
+}
+
+// Response is the reply of an admission webhook
+type Response struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+
+	// Labels are set on the claim by a mutating webhook. These replace the
+	// labels that are set by the claim.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// WebhookPolicy delegates the admission of the claims it selects to an
+// external HTTP endpoint
+type WebhookPolicy struct {
+	PolicyName string
+	Selector   Selector
+	URL        string
+
+	// Type is either mutating or validating
+	Type string
+
+	// FailurePolicy decides if the claim fails when the webhook can not be
+	// reached. Defaults to fail.
+	FailurePolicy string
+
+	Client *http.Client
+}
+
+// Name returns the name of the policy
+func (p *WebhookPolicy) Name() string {
+	return p.PolicyName
+}
+
+// Mutate sets the labels returned by the webhook on the claim. The webhook
+// may also deny the claim.
+func (p *WebhookPolicy) Mutate(pvc *v1.PersistentVolumeClaim) error {
+	resp, err := p.review(pvc)
+	if err != nil || resp == nil {
+		return err
+	}
+
+	setLabels(pvc, resp.Labels, true)
+	return nil
+}
+
+// Validate denies the claim if the webhook does not allow it
+func (p *WebhookPolicy) Validate(pvc *v1.PersistentVolumeClaim) error {
+	_, err := p.review(pvc)
+	return err
+}
+
+// review posts the claim to the webhook. A nil response is returned if the
+// policy does not select the claim or if the webhook is ignored.
+func (p *WebhookPolicy) review(pvc *v1.PersistentVolumeClaim) (*Response, error) {
+	if !p.Selector.Matches(pvc) {
+		return nil, nil
+	}
+
+	resp, err := p.post(&Review{Type: p.Type, Namespace: Namespace(pvc), Claim: pvc})
+	if err != nil {
+		if p.FailurePolicy == FailurePolicyIgnore {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Admission webhook '%s' failed: %v", p.PolicyName, err)
+	}
+
+	if !resp.Allowed {
+		reason := resp.Reason
+		if reason == "" {
+			reason = "denied by the admission webhook"
+		}
+		return nil, &DeniedError{Policy: p.PolicyName, Reason: reason}
+	}
+	return resp, nil
+}
+
+// post sends the review to the webhook & decodes its response
+func (p *WebhookPolicy) post(review *Review) (*Response, error) {
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+
+	httpResp, err := client.Post(p.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	b, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected response %s", httpResp.Status)
+	}
+
+	resp := &Response{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	return resp, nil
+}
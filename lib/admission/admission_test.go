@@ -0,0 +1,132 @@
+package admission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+)
+
+func makeClaim(name, ns string, labels map[string]string) *v1.PersistentVolumeClaim {
+	pvc := &v1.PersistentVolumeClaim{}
+	pvc.Name = name
+	pvc.Labels = map[string]string{string(v1.OrchNSLbl): ns}
+	for k, v := range labels {
+		pvc.Labels[k] = v
+	}
+	return pvc
+}
+
+func TestLabelPolicy(t *testing.T) {
+	image := string(v1.PVPReplicaImageLbl)
+	p := &LabelPolicy{
+		PolicyName: "default-image",
+		Selector:   Selector{Namespaces: []string{"team-*"}, Names: []string{"db-*"}},
+		Labels:     map[string]string{image: "openebs/jiva:0.4.0"},
+	}
+
+	cases := []struct {
+		pvc      *v1.PersistentVolumeClaim
+		expected string
+	}{
+		{makeClaim("db-1", "team-a", nil), "openebs/jiva:0.4.0"},
+		{makeClaim("db-1", "team-a", map[string]string{image: "custom"}), "custom"},
+		{makeClaim("web-1", "team-a", nil), ""},
+		{makeClaim("db-1", "prod", nil), ""},
+	}
+
+	for _, c := range cases {
+		if err := p.Mutate(c.pvc); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if actual := c.pvc.Labels[image]; actual != c.expected {
+			t.Fatalf("bad: %s/%s: expected: %q, got: %q", c.pvc.Labels[string(v1.OrchNSLbl)], c.pvc.Name, c.expected, actual)
+		}
+	}
+
+	p.Overwrite = true
+	pvc := makeClaim("db-1", "team-a", map[string]string{image: "custom"})
+	p.Mutate(pvc)
+	if pvc.Labels[image] != "openebs/jiva:0.4.0" {
+		t.Fatalf("bad: expected the label to be overwritten: %v", pvc.Labels)
+	}
+}
+
+func TestRulePolicy(t *testing.T) {
+	replicas := string(v1.PVPReplicaCountLbl)
+	p := &RulePolicy{
+		PolicyName: "prod-replicas",
+		Selector:   Selector{Namespaces: []string{"prod"}},
+		Forbid:     map[string][]string{replicas: {"1"}},
+		Require:    []string{string(v1.PVPStorageSizeLbl)},
+	}
+
+	sized := func(labels map[string]string) map[string]string {
+		labels[string(v1.PVPStorageSizeLbl)] = "1G"
+		return labels
+	}
+
+	cases := []struct {
+		pvc    *v1.PersistentVolumeClaim
+		denied bool
+	}{
+		{makeClaim("vol1", "prod", sized(map[string]string{replicas: "1"})), true},
+		{makeClaim("vol1", "prod", sized(map[string]string{replicas: "3"})), false},
+		{makeClaim("vol1", "prod", map[string]string{replicas: "3"}), true},
+		{makeClaim("vol1", "dev", map[string]string{replicas: "1"}), false},
+	}
+
+	for _, c := range cases {
+		err := p.Validate(c.pvc)
+		if IsDenied(err) != c.denied {
+			t.Fatalf("bad: %v: expected denied: %v, got: %v", c.pvc.Labels, c.denied, err)
+		}
+	}
+}
+
+func TestWebhookPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := &Review{}
+		if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+
+		resp := &Response{Allowed: review.Namespace != "prod", Reason: "prod is frozen"}
+		if review.Type == Mutating {
+			resp.Labels = map[string]string{"team": review.Claim.Name}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	chain := &Chain{
+		Mutators:   []Mutator{&WebhookPolicy{PolicyName: "team", URL: srv.URL, Type: Mutating}},
+		Validators: []Validator{&WebhookPolicy{PolicyName: "freeze", URL: srv.URL, Type: Validating}},
+	}
+
+	pvc := makeClaim("vol1", "dev", nil)
+	if err := chain.Mutate(pvc); err != nil || pvc.Labels["team"] != "vol1" {
+		t.Fatalf("bad: %v, err: %v", pvc.Labels, err)
+	}
+	if err := chain.Validate(pvc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	pvc = makeClaim("vol1", "prod", nil)
+	if err := chain.Validate(pvc); !IsDenied(err) || err.(*DeniedError).Reason != "prod is frozen" {
+		t.Fatalf("bad: expected a denial, got: %v", err)
+	}
+
+	// An unreachable webhook fails the claim unless it is ignored
+	p := &WebhookPolicy{PolicyName: "down", URL: "http://127.0.0.1:1", Type: Validating}
+	if err := p.Validate(pvc); err == nil || IsDenied(err) {
+		t.Fatalf("bad: expected a failure, got: %v", err)
+	}
+	p.FailurePolicy = FailurePolicyIgnore
+	if err := p.Validate(pvc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
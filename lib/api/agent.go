@@ -0,0 +1,60 @@
+package api
+
+import (
+	"bufio"
+
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+// Agent is used to deal with the maya api server itself
+type Agent struct {
+	client *Client
+}
+
+// Agent returns a handle on the maya api server
+func (c *Client) Agent() *Agent {
+	return &Agent{client: c}
+}
+
+// Self describes the maya api server
+func (a *Agent) Self() (*mapiv1.AgentSelf, error) {
+	self := &mapiv1.AgentSelf{}
+	if _, err := a.client.query("/latest/agent/self", self, nil); err != nil {
+		return nil, err
+	}
+	return self, nil
+}
+
+// Monitor streams the logs of the maya api server at the log level or
+// above. INFO is used if the log level is empty. The returned channel is
+// closed once the stream ends e.g. when the context of q is cancelled.
+func (a *Agent) Monitor(logLevel string, q *QueryOptions) (<-chan string, error) {
+	r := a.client.newRequest("GET", "/latest/agent/monitor")
+	r.setQueryOptions(a.client, q)
+	if logLevel != "" {
+		r.params.Set("log_level", logLevel)
+	}
+
+	_, resp, err := a.client.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	logCh := make(chan string, 64)
+	go func() {
+		defer close(logCh)
+		defer resp.Body.Close()
+
+		ctx := q.Context()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			select {
+			case logCh <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return logCh, nil
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/loghelper"
+)
+
+func TestAgent_Self(t *testing.T) {
+	c, s := makeClient(t, func(mc *config.MayaConfig) {
+		mc.NodeName = "node-a"
+		mc.Region = "us-east"
+	}, nil)
+	defer s.Cleanup()
+
+	self, err := c.Agent().Self()
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if self.NodeName != "node-a" || self.Region != "us-east" {
+		t.Fatalf("ERR: unexpected self: %#v", self)
+	}
+}
+
+func TestAgent_Monitor(t *testing.T) {
+	c, s := makeClient(t, func(mc *config.MayaConfig) {
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "secret"}
+	}, func(conf *Config) {
+		conf.Token = "secret"
+	})
+	defer s.Cleanup()
+
+	registrar := loghelper.NewLogRegistrar(16)
+	s.Maya.SetLogRegistrar(registrar)
+	registrar.Write([]byte("[DEBUG] buffered debug\n"))
+	registrar.Write([]byte("[WARN] buffered warn\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logCh, err := c.Agent().Monitor("warn", (&QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	select {
+	case line := <-logCh:
+		if !strings.Contains(line, "buffered warn") {
+			t.Fatalf("ERR: unexpected line: %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ERR: no line was streamed")
+	}
+
+	// The stream ends along with the context
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-logCh:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("ERR: stream did not end on cancel")
+		}
+	}
+}
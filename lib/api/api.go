@@ -0,0 +1,457 @@
+// Package api is the Go client of the maya api server HTTP API.
+//
+// NOTE:
+//    The volumes are served as the typed resources of lib/api/v1 i.e. the
+// same types the server encodes.
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AddrEnv is the env var that sets the address of maya api server
+	AddrEnv = "MAPI_ADDR"
+
+	// TokenEnv is the env var that sets the management token
+	TokenEnv = "MAPI_TOKEN"
+
+	// CACertEnv is the env var that sets the CA certificate file
+	CACertEnv = "MAPI_CACERT"
+
+	// ClientCertEnv is the env var that sets the client certificate file
+	ClientCertEnv = "MAPI_CLIENT_CERT"
+
+	// ClientKeyEnv is the env var that sets the client key file
+	ClientKeyEnv = "MAPI_CLIENT_KEY"
+
+	// SkipVerifyEnv is the env var that disables the verification of the
+	// server certificate
+	SkipVerifyEnv = "MAPI_SKIP_VERIFY"
+)
+
+const (
+	// DefaultAddress is the address of a local maya api server
+	DefaultAddress = "http://127.0.0.1:5656"
+
+	// tokenHeader carries the management token
+	tokenHeader = "X-Maya-Token"
+
+	// indexHeader carries the index a blocking query was served at
+	indexHeader = "X-Maya-Index"
+)
+
+// Config is the configuration of a client
+type Config struct {
+	// Address of maya api server e.g. http://127.0.0.1:5656
+	Address string
+
+	// Token is the management token that is sent with every request
+	Token string
+
+	// Region is the region the requests are served by unless a query sets
+	// its own
+	Region string
+
+	// TLSConfig is used if the address is https
+	TLSConfig *TLSConfig
+
+	// HttpClient is the client to use. A client is built out of the TLS
+	// config if it is not set.
+	HttpClient *http.Client
+
+	// WaitTime is the wait of a blocking query that does not set its own
+	WaitTime time.Duration
+}
+
+// TLSConfig is the TLS configuration of a client
+type TLSConfig struct {
+	// CACert is the PEM file of the CA that signed the server certificate
+	CACert string
+
+	// ClientCert & ClientKey are the PEM files of the client certificate
+	ClientCert string
+	ClientKey  string
+
+	// Insecure skips the verification of the server certificate
+	Insecure bool
+}
+
+// DefaultConfig returns the default configuration of a client. It is
+// overridden by the MAPI_* env vars.
+func DefaultConfig() *Config {
+	conf := &Config{
+		Address:   DefaultAddress,
+		TLSConfig: &TLSConfig{},
+	}
+
+	if addr := os.Getenv(AddrEnv); addr != "" {
+		conf.Address = addr
+	}
+	conf.Token = os.Getenv(TokenEnv)
+	conf.TLSConfig.CACert = os.Getenv(CACertEnv)
+	conf.TLSConfig.ClientCert = os.Getenv(ClientCertEnv)
+	conf.TLSConfig.ClientKey = os.Getenv(ClientKeyEnv)
+	if v := os.Getenv(SkipVerifyEnv); v != "" {
+		conf.TLSConfig.Insecure, _ = strconv.ParseBool(v)
+	}
+
+	return conf
+}
+
+// tlsConfig builds the TLS configuration of the http client
+func (t *TLSConfig) tlsConfig() (*tls.Config, error) {
+	conf := &tls.Config{InsecureSkipVerify: t.Insecure}
+
+	if t.CACert != "" {
+		pem, err := ioutil.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to parse CA cert %s", t.CACert)
+		}
+		conf.RootCAs = pool
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert: %v", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return conf, nil
+}
+
+// Client talks to a maya api server
+type Client struct {
+	config Config
+	addr   *url.URL
+}
+
+// NewClient returns a client of the configured maya api server
+func NewClient(config *Config) (*Client, error) {
+	defaults := DefaultConfig()
+	if config == nil {
+		config = defaults
+	}
+
+	conf := *config
+	if conf.Address == "" {
+		conf.Address = defaults.Address
+	}
+
+	addr, err := url.Parse(conf.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address '%s': %v", conf.Address, err)
+	}
+	if addr.Scheme != "http" && addr.Scheme != "https" {
+		return nil, fmt.Errorf("invalid address '%s': scheme must be http or https", conf.Address)
+	}
+
+	if conf.HttpClient == nil {
+		// NOTE:
+		//    The transport mirrors http.DefaultTransport as Transport.Clone
+		// is not available in the Go release this builds with.
+		transport := &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+		if conf.TLSConfig != nil {
+			if transport.TLSClientConfig, err = conf.TLSConfig.tlsConfig(); err != nil {
+				return nil, err
+			}
+		}
+		conf.HttpClient = &http.Client{Transport: transport}
+	}
+
+	return &Client{config: conf, addr: addr}, nil
+}
+
+// Address returns the address of maya api server
+func (c *Client) Address() string {
+	return c.config.Address
+}
+
+// QueryOptions are the options of a read
+type QueryOptions struct {
+	// Region overrides the region of the client
+	Region string
+
+	// WaitIndex makes the query a blocking query. It returns once the
+	// server index moves past WaitIndex or WaitTime elapses.
+	WaitIndex uint64
+
+	// WaitTime is the maximum wait of a blocking query
+	WaitTime time.Duration
+
+	// MetaToken is the session token of a metadata query
+	MetaToken string
+
+	// Params are additional query params e.g. health
+	Params map[string]string
+
+	ctx context.Context
+}
+
+// WithContext returns a copy of the options that cancels the query along
+// with the context
+func (o *QueryOptions) WithContext(ctx context.Context) *QueryOptions {
+	o2 := &QueryOptions{}
+	if o != nil {
+		*o2 = *o
+	}
+	o2.ctx = ctx
+	return o2
+}
+
+// Context returns the context of the query. The background context is
+// returned if none was set.
+func (o *QueryOptions) Context() context.Context {
+	if o != nil && o.ctx != nil {
+		return o.ctx
+	}
+	return context.Background()
+}
+
+// WriteOptions are the options of a write
+type WriteOptions struct {
+	// Region overrides the region of the client
+	Region string
+
+	ctx context.Context
+}
+
+// WithContext returns a copy of the options that cancels the write along
+// with the context
+func (o *WriteOptions) WithContext(ctx context.Context) *WriteOptions {
+	o2 := &WriteOptions{}
+	if o != nil {
+		*o2 = *o
+	}
+	o2.ctx = ctx
+	return o2
+}
+
+// Context returns the context of the write. The background context is
+// returned if none was set.
+func (o *WriteOptions) Context() context.Context {
+	if o != nil && o.ctx != nil {
+		return o.ctx
+	}
+	return context.Background()
+}
+
+// QueryMeta is the meta data of a read
+type QueryMeta struct {
+	// LastIndex is the index the query was served at. It is the WaitIndex
+	// of the next blocking query.
+	LastIndex uint64
+
+	// RequestTime is the round trip time of the query
+	RequestTime time.Duration
+}
+
+// UnexpectedResponseError is returned if the server does not respond with a
+// 2xx code
+type UnexpectedResponseError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("Unexpected response code: %d (%s)", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// IsNotFound returns true if the error is a 404 response
+func IsNotFound(err error) bool {
+	e, ok := err.(*UnexpectedResponseError)
+	return ok && e.StatusCode == 404
+}
+
+// request is a single request to the server
+type request struct {
+	method string
+	path   string
+	params url.Values
+	header http.Header
+	body   io.Reader
+	ctx    context.Context
+}
+
+// newRequest builds a request against the path of the server
+func (c *Client) newRequest(method, path string) *request {
+	r := &request{
+		method: method,
+		path:   path,
+		params: url.Values{},
+		header: http.Header{},
+		ctx:    context.Background(),
+	}
+	if c.config.Region != "" {
+		r.params.Set("region", c.config.Region)
+	}
+	if c.config.Token != "" {
+		r.header.Set(tokenHeader, c.config.Token)
+	}
+	return r
+}
+
+// setQueryOptions applies the options of a read to the request
+func (r *request) setQueryOptions(c *Client, q *QueryOptions) {
+	if q == nil {
+		return
+	}
+	if q.Region != "" {
+		r.params.Set("region", q.Region)
+	}
+	if q.WaitIndex != 0 {
+		r.params.Set("index", strconv.FormatUint(q.WaitIndex, 10))
+		wait := q.WaitTime
+		if wait == 0 {
+			wait = c.config.WaitTime
+		}
+		if wait != 0 {
+			r.params.Set("wait", wait.String())
+		}
+	}
+	if q.MetaToken != "" {
+		r.header.Set("X-aws-ec2-metadata-token", q.MetaToken)
+	}
+	for k, v := range q.Params {
+		r.params.Set(k, v)
+	}
+	r.ctx = q.Context()
+}
+
+// setWriteOptions applies the options of a write to the request
+func (r *request) setWriteOptions(w *WriteOptions) {
+	if w == nil {
+		return
+	}
+	if w.Region != "" {
+		r.params.Set("region", w.Region)
+	}
+	r.ctx = w.Context()
+}
+
+// setJSONBody encodes the object as the JSON body of the request
+func (r *request) setJSONBody(obj interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	r.body = bytes.NewReader(b)
+	r.header.Set("Content-Type", "application/json")
+	return nil
+}
+
+// doRequest sends the request. A response other than 2xx is returned as an
+// UnexpectedResponseError. The caller must close the body of the response.
+func (c *Client) doRequest(r *request) (time.Duration, *http.Response, error) {
+	u := *c.addr
+	u.Path = strings.TrimSuffix(u.Path, "/") + r.path
+	u.RawQuery = r.params.Encode()
+
+	req, err := http.NewRequest(r.method, u.String(), r.body)
+	if err != nil {
+		return 0, nil, err
+	}
+	req = req.WithContext(r.ctx)
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+
+	start := time.Now()
+	resp, err := c.config.HttpClient.Do(req)
+	diff := time.Since(start)
+	if err != nil {
+		return diff, nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return diff, nil, &UnexpectedResponseError{StatusCode: resp.StatusCode, Body: string(b)}
+	}
+	return diff, resp, nil
+}
+
+// query reads the path & decodes the JSON response into out
+func (c *Client) query(path string, out interface{}, q *QueryOptions) (*QueryMeta, error) {
+	r := c.newRequest("GET", path)
+	r.setQueryOptions(c, q)
+
+	rtt, resp, err := c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	qm := &QueryMeta{RequestTime: rtt}
+	if idx := resp.Header.Get(indexHeader); idx != "" {
+		if qm.LastIndex, err = strconv.ParseUint(idx, 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", indexHeader, err)
+		}
+	}
+
+	if err := decodeBody(resp, out); err != nil {
+		return nil, err
+	}
+	return qm, nil
+}
+
+// write sends the JSON encoded object to the path & decodes the JSON
+// response into out if it is not nil
+func (c *Client) write(method, path string, in, out interface{}, w *WriteOptions) error {
+	r := c.newRequest(method, path)
+	r.setWriteOptions(w)
+	if in != nil {
+		if err := r.setJSONBody(in); err != nil {
+			return err
+		}
+	}
+
+	_, resp, err := c.doRequest(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		return decodeBody(resp, out)
+	}
+	return nil
+}
+
+// decodeBody decodes the JSON body of the response
+func decodeBody(resp *http.Response, out interface{}) error {
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/server"
+)
+
+// makeClient starts a test server & returns a client of it
+func makeClient(t *testing.T, fnmc func(mc *config.MayaConfig), fncc func(c *Config)) (*Client, *server.TestServer) {
+	s := server.NewTestServer(t, fnmc)
+
+	conf := DefaultConfig()
+	conf.Address = s.HTTPAddr
+	if fncc != nil {
+		fncc(conf)
+	}
+
+	c, err := NewClient(conf)
+	if err != nil {
+		s.Cleanup()
+		t.Fatalf("ERR: %v", err)
+	}
+	return c, s
+}
+
+func TestDefaultConfig_Env(t *testing.T) {
+	os.Setenv(AddrEnv, "https://10.0.0.1:5656")
+	os.Setenv(TokenEnv, "secret")
+	os.Setenv(SkipVerifyEnv, "true")
+	defer os.Unsetenv(AddrEnv)
+	defer os.Unsetenv(TokenEnv)
+	defer os.Unsetenv(SkipVerifyEnv)
+
+	conf := DefaultConfig()
+	if conf.Address != "https://10.0.0.1:5656" || conf.Token != "secret" || !conf.TLSConfig.Insecure {
+		t.Fatalf("ERR: env vars are not applied: %#v", conf)
+	}
+}
+
+func TestNewClient_InvalidAddress(t *testing.T) {
+	for _, addr := range []string{"10.0.0.1:5656", "tcp://10.0.0.1:5656", "http://[::1"} {
+		if _, err := NewClient(&Config{Address: addr}); err == nil {
+			t.Fatalf("ERR: expected an error for address %q", addr)
+		}
+	}
+}
+
+func TestClient_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"nodeName": "tls"}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "mapiclient")
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.TLS.Certificates[0].Certificate[0]})
+	if err := ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	// The server certificate is verified against the CA
+	c, err := NewClient(&Config{Address: ts.URL, TLSConfig: &TLSConfig{CACert: caFile}})
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	self, err := c.Agent().Self()
+	if err != nil || self.NodeName != "tls" {
+		t.Fatalf("ERR: expected the TLS server, got: %v, %v", self, err)
+	}
+
+	// The server certificate is not trusted without the CA
+	c, err = NewClient(&Config{Address: ts.URL, TLSConfig: &TLSConfig{}})
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if _, err := c.Agent().Self(); err == nil {
+		t.Fatalf("ERR: expected an untrusted certificate error")
+	}
+
+	// A missing CA file
+	if _, err := NewClient(&Config{Address: ts.URL, TLSConfig: &TLSConfig{CACert: filepath.Join(dir, "none.pem")}}); err == nil {
+		t.Fatalf("ERR: expected an error for a missing CA file")
+	}
+}
+
+func TestClient_QueryOptions(t *testing.T) {
+	var got *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("X-Maya-Index", "42")
+		w.Write([]byte(`{"kind": "VSMList", "items": []}`))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(&Config{Address: ts.URL, Token: "secret", Region: "us-east"})
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	q := &QueryOptions{
+		WaitIndex: 41,
+		WaitTime:  10 * time.Second,
+		Params:    map[string]string{"health": "Degraded"},
+	}
+	l, qm, err := c.Volumes().List(q)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if l.Kind != mapiv1.VSMListKind || qm.LastIndex != 42 {
+		t.Fatalf("ERR: unexpected response: %#v, %#v", l, qm)
+	}
+
+	query := got.URL.Query()
+	if got.URL.Path != "/v2/volumes/" || query.Get("index") != "41" || query.Get("wait") != "10s" ||
+		query.Get("health") != "Degraded" || query.Get("region") != "us-east" {
+		t.Fatalf("ERR: unexpected request: %s", got.URL)
+	}
+	if got.Header.Get("X-Maya-Token") != "secret" {
+		t.Fatalf("ERR: expected the management token")
+	}
+}
+
+func TestClient_ContextCancel(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Cleanup()
+
+	// The query blocks at the current index till the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := c.Volumes().List((&QueryOptions{WaitIndex: 1}).WithContext(ctx))
+	if err == nil || ctx.Err() == nil {
+		t.Fatalf("ERR: expected the query to be cancelled, got: %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("ERR: query was not cancelled along with its context")
+	}
+}
+
+func TestClient_UnexpectedResponse(t *testing.T) {
+	c, s := makeClient(t, func(mc *config.MayaConfig) {
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "secret"}
+	}, nil)
+	defer s.Cleanup()
+
+	_, err := c.Agent().Monitor("", nil)
+	if e, ok := err.(*UnexpectedResponseError); !ok || e.StatusCode != 403 {
+		t.Fatalf("ERR: expected a 403 response, got: %v", err)
+	}
+	if IsNotFound(err) {
+		t.Fatalf("ERR: 403 is not a not found")
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetaData is used to read the metadata served by maya api server
+type MetaData struct {
+	client *Client
+}
+
+// MetaData returns a handle on the metadata
+func (c *Client) MetaData() *MetaData {
+	return &MetaData{client: c}
+}
+
+// Token issues a session token for the metadata. It is passed as the
+// MetaToken of the queries.
+func (m *MetaData) Token(ttl time.Duration) (string, error) {
+	r := m.client.newRequest("PUT", "/latest/api/token")
+	r.header.Set("X-aws-ec2-metadata-token-ttl-seconds", strconv.Itoa(int(ttl/time.Second)))
+
+	_, resp, err := m.client.doRequest(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Get reads the metadata at the path e.g. placement/availability-zone. A
+// leaf is returned as its value while a directory is returned as the
+// newline separated list of its children.
+func (m *MetaData) Get(path string, q *QueryOptions) (string, error) {
+	r := m.client.newRequest("GET", "/latest/meta-data/"+strings.TrimPrefix(path, "/"))
+	r.setQueryOptions(m.client, q)
+
+	_, resp, err := m.client.doRequest(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestMetaData(t *testing.T) {
+	c, s := makeClient(t, func(mc *config.MayaConfig) {
+		mc.Metadata = &config.Metadata{AvailabilityZone: "zone-a", RequireToken: true}
+	}, nil)
+	defer s.Cleanup()
+
+	// A token is required by config
+	if _, err := c.MetaData().Get("placement/availability-zone", nil); err == nil {
+		t.Fatalf("ERR: expected an error without a token")
+	}
+
+	token, err := c.MetaData().Token(time.Minute)
+	if err != nil || token == "" {
+		t.Fatalf("ERR: expected a token, got: %q, %v", token, err)
+	}
+
+	q := &QueryOptions{MetaToken: token}
+	zone, err := c.MetaData().Get("placement/availability-zone", q)
+	if err != nil || zone != "zone-a" {
+		t.Fatalf("ERR: expected zone-a, got: %q, %v", zone, err)
+	}
+
+	dir, err := c.MetaData().Get("/", q)
+	if err != nil || !strings.Contains(dir, "placement/") {
+		t.Fatalf("ERR: expected the metadata tree, got: %q, %v", dir, err)
+	}
+}
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"time"
+)
+
+// AgentSelf describes a maya api server
+type AgentSelf struct {
+	Version           string     `json:"version"`
+	Revision          string     `json:"revision"`
+	VersionPrerelease string     `json:"versionPrerelease,omitempty"`
+	NodeName          string     `json:"nodeName"`
+	Region            string     `json:"region"`
+	Datacenter        string     `json:"datacenter"`
+	AdvertiseAddr     string     `json:"advertiseAddr"`
+	LogLevel          string     `json:"logLevel"`
+	LogLevelRevertAt  *time.Time `json:"logLevelRevertAt,omitempty"`
+	StartTime         time.Time  `json:"startTime"`
+	Uptime            string     `json:"uptime"`
+	UptimeSeconds     int64      `json:"uptimeSeconds"`
+}
@@ -0,0 +1,250 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+// runningStatus is the status reported for a running controller or replica
+const runningStatus = "Running"
+
+// vsmAnnotations are the annotations that are converted into typed fields of
+// a VSM. These are not carried over as annotations of the VSM.
+var vsmAnnotations = []mayav1.MayaAPIServiceOutputLabel{
+	mayav1.ReplicaStatusAPILbl,
+	mayav1.ControllerStatusAPILbl,
+	mayav1.TargetPortalsAPILbl,
+	mayav1.ClusterIPsAPILbl,
+	mayav1.ReplicaIPsAPILbl,
+	mayav1.ControllerIPsAPILbl,
+	mayav1.IQNAPILbl,
+	mayav1.VolumeSizeAPILbl,
+	mayav1.ReplicaCountAPILbl,
+}
+
+// FromPersistentVolume converts a persistent volume whose VSM details are set
+// as annotations into a typed VSM
+func FromPersistentVolume(pv *mayav1.PersistentVolume) *VSM {
+	if pv == nil {
+		return nil
+	}
+
+	vsm := &VSM{}
+	vsm.Kind = VSMKind
+	vsm.APIVersion = APIVersion
+	vsm.ObjectMeta = pv.ObjectMeta
+	vsm.Annotations = nil
+
+	annotations := pv.Annotations
+	get := func(key mayav1.MayaAPIServiceOutputLabel) string {
+		return strings.TrimSpace(annotations[string(key)])
+	}
+
+	// Annotations that are not converted are retained as is
+	for k, v := range annotations {
+		if isVSMAnnotation(k) {
+			continue
+		}
+		if vsm.Annotations == nil {
+			vsm.Annotations = map[string]string{}
+		}
+		vsm.Annotations[k] = v
+	}
+
+	// Spec
+	vsm.Spec.Capacity = get(mayav1.VolumeSizeAPILbl)
+	if vsm.Spec.Capacity != "" {
+		if q, err := mayav1.ParseQuantity(vsm.Spec.Capacity); err == nil {
+			vsm.Spec.CapacityBytes = q.Value()
+		}
+	} else if q, ok := pv.Spec.Capacity[mayav1.ResourceName("storage")]; ok {
+		vsm.Spec.Capacity = q.String()
+		vsm.Spec.CapacityBytes = q.Value()
+	}
+
+	if count, err := strconv.Atoi(get(mayav1.ReplicaCountAPILbl)); err == nil {
+		vsm.Spec.ReplicaCount = count
+	}
+
+	// Status
+	vsm.Status.Phase = pv.Status.Phase
+
+	ctrlIPs := splitValues(get(mayav1.ControllerIPsAPILbl))
+	ctrlStatuses := splitValues(get(mayav1.ControllerStatusAPILbl))
+	for i := 0; i < maxLen(ctrlIPs, ctrlStatuses); i++ {
+		vsm.Status.Controllers = append(vsm.Status.Controllers, Controller{
+			IP:     valueAt(ctrlIPs, i),
+			Status: valueAt(ctrlStatuses, i),
+		})
+	}
+
+	repIPs := splitValues(get(mayav1.ReplicaIPsAPILbl))
+	repStatuses := splitValues(get(mayav1.ReplicaStatusAPILbl))
+	repNodes := splitValues(get(ReplicaNodesAPILbl))
+	for i := 0; i < maxLen(repIPs, repStatuses); i++ {
+		vsm.Status.Replicas = append(vsm.Status.Replicas, Replica{
+			IP:     valueAt(repIPs, i),
+			Status: valueAt(repStatuses, i),
+			Node:   valueAt(repNodes, i),
+		})
+	}
+
+	vsm.Status.Target = ISCSITarget{
+		IQN:        get(mayav1.IQNAPILbl),
+		Portals:    splitValues(get(mayav1.TargetPortalsAPILbl)),
+		ClusterIPs: splitValues(get(mayav1.ClusterIPsAPILbl)),
+	}
+
+	vsm.Status.Health, _ = vsm.Health()
+	vsm.Status.Conditions = []Condition{
+		controllerReadyCondition(vsm),
+		replicasReadyCondition(vsm),
+		healthCondition(vsm),
+	}
+
+	return vsm
+}
+
+// FromPersistentVolumeList converts a list of persistent volumes into a list
+// of typed VSMs
+func FromPersistentVolumeList(pvl *mayav1.PersistentVolumeList) *VSMList {
+	l := &VSMList{}
+	l.Kind = VSMListKind
+	l.APIVersion = APIVersion
+	l.Items = []VSM{}
+
+	if pvl == nil {
+		return l
+	}
+
+	l.ListMeta = pvl.ListMeta
+	for i := range pvl.Items {
+		l.Items = append(l.Items, *FromPersistentVolume(&pvl.Items[i]))
+	}
+
+	return l
+}
+
+// ReadyReplicas returns the count of replicas that are running
+func (v *VSM) ReadyReplicas() int {
+	count := 0
+	for _, r := range v.Status.Replicas {
+		if r.Status == runningStatus {
+			count++
+		}
+	}
+	return count
+}
+
+// DesiredReplicas returns the count of replicas desired for the VSM. The
+// count of observed replicas is used if the desired count is not known.
+func (v *VSM) DesiredReplicas() int {
+	if v.Spec.ReplicaCount > 0 {
+		return v.Spec.ReplicaCount
+	}
+	return len(v.Status.Replicas)
+}
+
+// Condition returns the condition of the given type if available
+func (v *VSM) Condition(ct ConditionType) (Condition, bool) {
+	for _, c := range v.Status.Conditions {
+		if c.Type == ct {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
+
+// controllerReadyCondition observes if all the controllers of the VSM are
+// running
+func controllerReadyCondition(v *VSM) Condition {
+	c := Condition{Type: ControllerReady}
+
+	if len(v.Status.Controllers) == 0 {
+		c.Status = ConditionFalse
+		c.Reason = "ControllerMissing"
+		c.Message = "No controller was found"
+		return c
+	}
+
+	for _, ctrl := range v.Status.Controllers {
+		if ctrl.Status != runningStatus {
+			c.Status = ConditionFalse
+			c.Reason = "ControllerNotRunning"
+			c.Message = fmt.Sprintf("Controller '%s' is in '%s' status", ctrl.IP, ctrl.Status)
+			return c
+		}
+	}
+
+	c.Status = ConditionTrue
+	c.Reason = "ControllerRunning"
+	return c
+}
+
+// replicasReadyCondition observes if all the desired replicas of the VSM are
+// running
+func replicasReadyCondition(v *VSM) Condition {
+	c := Condition{Type: ReplicasReady}
+
+	desired := v.DesiredReplicas()
+	ready := v.ReadyReplicas()
+
+	c.Message = fmt.Sprintf("%d of %d replicas are running", ready, desired)
+	switch {
+	case desired == 0:
+		c.Status = ConditionUnknown
+		c.Reason = "ReplicaCountUnknown"
+	case ready >= desired:
+		c.Status = ConditionTrue
+		c.Reason = "ReplicasRunning"
+	default:
+		c.Status = ConditionFalse
+		c.Reason = "ReplicasNotRunning"
+	}
+
+	return c
+}
+
+// isVSMAnnotation returns true if the annotation is converted into a typed
+// field of a VSM
+func isVSMAnnotation(key string) bool {
+	if key == HealthAPILbl || key == HealthReasonAPILbl || key == ReplicaNodesAPILbl {
+		return true
+	}
+
+	for _, a := range vsmAnnotations {
+		if string(a) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// splitValues splits a comma separated annotation value into its individual
+// non empty values
+func splitValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func valueAt(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+func maxLen(a, b []string) int {
+	if len(a) > len(b) {
+		return len(a)
+	}
+	return len(b)
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+func TestFromPersistentVolume(t *testing.T) {
+	pv := &mayav1.PersistentVolume{}
+	pv.Name = "vol1"
+	pv.Annotations = map[string]string{
+		string(mayav1.ControllerIPsAPILbl):    "10.1.1.1",
+		string(mayav1.ControllerStatusAPILbl): "Running",
+		string(mayav1.ReplicaIPsAPILbl):       "10.1.1.2,10.1.1.3",
+		string(mayav1.ReplicaStatusAPILbl):    "Running,Pending",
+		ReplicaNodesAPILbl:                    "node-a",
+		string(mayav1.TargetPortalsAPILbl):    "10.0.0.1:3260",
+		string(mayav1.ClusterIPsAPILbl):       "10.0.0.1",
+		string(mayav1.IQNAPILbl):              "iqn.2016-09.com.openebs.jiva:vol1",
+		string(mayav1.VolumeSizeAPILbl):       "1G",
+		string(mayav1.ReplicaCountAPILbl):     "2",
+		"some/other":                          "value",
+	}
+
+	vsm := FromPersistentVolume(pv)
+
+	if vsm.Kind != VSMKind || vsm.APIVersion != APIVersion {
+		t.Fatalf("bad type meta: %v", vsm.TypeMeta)
+	}
+
+	if vsm.Name != "vol1" {
+		t.Fatalf("expected name: vol1, got: %s", vsm.Name)
+	}
+
+	if !reflect.DeepEqual(vsm.Annotations, map[string]string{"some/other": "value"}) {
+		t.Fatalf("bad annotations: %v", vsm.Annotations)
+	}
+
+	if vsm.Spec.Capacity != "1G" || vsm.Spec.CapacityBytes != 1000000000 {
+		t.Fatalf("bad capacity: %s (%d)", vsm.Spec.Capacity, vsm.Spec.CapacityBytes)
+	}
+
+	if vsm.Spec.ReplicaCount != 2 {
+		t.Fatalf("expected replica count: 2, got: %d", vsm.Spec.ReplicaCount)
+	}
+
+	expectedCtrls := []Controller{{IP: "10.1.1.1", Status: "Running"}}
+	if !reflect.DeepEqual(vsm.Status.Controllers, expectedCtrls) {
+		t.Fatalf("expected controllers: %v, got: %v", expectedCtrls, vsm.Status.Controllers)
+	}
+
+	expectedReps := []Replica{
+		{IP: "10.1.1.2", Status: "Running", Node: "node-a"},
+		{IP: "10.1.1.3", Status: "Pending"},
+	}
+	if !reflect.DeepEqual(vsm.Status.Replicas, expectedReps) {
+		t.Fatalf("expected replicas: %v, got: %v", expectedReps, vsm.Status.Replicas)
+	}
+
+	if vsm.Status.Target.IQN != "iqn.2016-09.com.openebs.jiva:vol1" {
+		t.Fatalf("bad iqn: %s", vsm.Status.Target.IQN)
+	}
+
+	if !reflect.DeepEqual(vsm.Status.Target.Portals, []string{"10.0.0.1:3260"}) {
+		t.Fatalf("bad portals: %v", vsm.Status.Target.Portals)
+	}
+
+	if c, _ := vsm.Condition(ControllerReady); c.Status != ConditionTrue {
+		t.Fatalf("expected controller ready, got: %v", c)
+	}
+
+	if c, _ := vsm.Condition(ReplicasReady); c.Status != ConditionFalse {
+		t.Fatalf("expected replicas not ready, got: %v", c)
+	}
+}
+
+func TestFromPersistentVolumeNoAnnotations(t *testing.T) {
+	pv := &mayav1.PersistentVolume{}
+	pv.Name = "vol1"
+
+	vsm := FromPersistentVolume(pv)
+
+	if len(vsm.Status.Controllers) != 0 || len(vsm.Status.Replicas) != 0 {
+		t.Fatalf("expected no controllers & replicas, got: %v", vsm.Status)
+	}
+
+	if c, _ := vsm.Condition(ControllerReady); c.Reason != "ControllerMissing" {
+		t.Fatalf("expected missing controller, got: %v", c)
+	}
+
+	if c, _ := vsm.Condition(ReplicasReady); c.Status != ConditionUnknown {
+		t.Fatalf("expected unknown replicas condition, got: %v", c)
+	}
+}
+
+func TestFromPersistentVolumeList(t *testing.T) {
+	if l := FromPersistentVolumeList(nil); l.Kind != VSMListKind || len(l.Items) != 0 {
+		t.Fatalf("bad list for nil: %v", l)
+	}
+
+	pvl := &mayav1.PersistentVolumeList{
+		Items: []mayav1.PersistentVolume{{}, {}},
+	}
+
+	if l := FromPersistentVolumeList(pvl); len(l.Items) != 2 {
+		t.Fatalf("expected 2 items, got: %d", len(l.Items))
+	}
+}
@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VSMHealth is the overall health of a VSM
+type VSMHealth string
+
+const (
+	// Healthy means the controller & all the desired replicas are running
+	Healthy VSMHealth = "Healthy"
+
+	// Degraded means the controller is running but some of the desired
+	// replicas are not
+	Degraded VSMHealth = "Degraded"
+
+	// Offline means either the controller or all of the replicas are not
+	// running. The VSM can not serve IO.
+	Offline VSMHealth = "Offline"
+)
+
+const (
+	// HealthAPILbl is the annotation set against a persistent volume with
+	// the VSM's health
+	HealthAPILbl = "vsm.openebs.io/health"
+
+	// HealthReasonAPILbl is the annotation set against a persistent volume
+	// with the human readable reason of the VSM's health
+	HealthReasonAPILbl = "vsm.openebs.io/health-reason"
+
+	// ReplicaNodesAPILbl is the annotation set against a persistent volume
+	// with the comma separated nodes of the replicas. The nodes are in the
+	// same order as the replica IPs.
+	ReplicaNodesAPILbl = "vsm.openebs.io/replica-nodes"
+)
+
+// HealthCondition is True when the VSM is Healthy. Its reason is the VSM's
+// health.
+const HealthCondition ConditionType = "Healthy"
+
+// ParseHealth converts the given value into one of the VSM health values. The
+// comparison is case insensitive.
+func ParseHealth(value string) (VSMHealth, error) {
+	for _, h := range []VSMHealth{Healthy, Degraded, Offline} {
+		if strings.EqualFold(value, string(h)) {
+			return h, nil
+		}
+	}
+	return "", fmt.Errorf("Invalid health '%s': valid values are %s, %s & %s", value, Healthy, Degraded, Offline)
+}
+
+// Health computes the overall health of the VSM from the readiness of its
+// controllers & the count of running replicas relative to the desired count.
+// It also returns the human readable reason for the health.
+func (v *VSM) Health() (VSMHealth, string) {
+	if c := controllerReadyCondition(v); c.Status != ConditionTrue {
+		return Offline, c.Message
+	}
+
+	desired := v.DesiredReplicas()
+	ready := v.ReadyReplicas()
+
+	switch {
+	case desired == 0:
+		return Offline, "No replica was found"
+	case ready == 0:
+		return Offline, fmt.Sprintf("None of the %d replicas are running", desired)
+	case ready < desired:
+		return Degraded, fmt.Sprintf("%d of %d replicas are running", ready, desired)
+	default:
+		return Healthy, fmt.Sprintf("Controller & %d of %d replicas are running", ready, desired)
+	}
+}
+
+// healthCondition observes the overall health of the VSM
+func healthCondition(v *VSM) Condition {
+	health, reason := v.Health()
+
+	c := Condition{
+		Type:    HealthCondition,
+		Status:  ConditionFalse,
+		Reason:  string(health),
+		Message: reason,
+	}
+	if health == Healthy {
+		c.Status = ConditionTrue
+	}
+
+	return c
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"testing"
+)
+
+func TestVSMHealth(t *testing.T) {
+	cases := []struct {
+		Controllers []Controller
+		Replicas    []Replica
+		Desired     int
+		Expected    VSMHealth
+	}{
+		{
+			[]Controller{{Status: "Running"}},
+			[]Replica{{Status: "Running"}, {Status: "Running"}},
+			2,
+			Healthy,
+		},
+		{
+			[]Controller{{Status: "Running"}},
+			[]Replica{{Status: "Running"}, {Status: "Pending"}},
+			2,
+			Degraded,
+		},
+		{
+			[]Controller{{Status: "Running"}},
+			[]Replica{{Status: "Running"}},
+			3,
+			Degraded,
+		},
+		{
+			[]Controller{{Status: "Pending"}},
+			[]Replica{{Status: "Running"}, {Status: "Running"}},
+			2,
+			Offline,
+		},
+		{
+			nil,
+			[]Replica{{Status: "Running"}},
+			1,
+			Offline,
+		},
+		{
+			[]Controller{{Status: "Running"}},
+			[]Replica{{Status: "Pending"}},
+			1,
+			Offline,
+		},
+	}
+
+	for i, tc := range cases {
+		vsm := &VSM{}
+		vsm.Spec.ReplicaCount = tc.Desired
+		vsm.Status.Controllers = tc.Controllers
+		vsm.Status.Replicas = tc.Replicas
+
+		health, reason := vsm.Health()
+		if health != tc.Expected {
+			t.Fatalf("case %d: expected: %s, got: %s (%s)", i, tc.Expected, health, reason)
+		}
+
+		if reason == "" {
+			t.Fatalf("case %d: expected a reason for health %s", i, health)
+		}
+	}
+}
+
+func TestParseHealth(t *testing.T) {
+	if h, err := ParseHealth("degraded"); err != nil || h != Degraded {
+		t.Fatalf("expected: %s, got: %s, err: %v", Degraded, h, err)
+	}
+
+	if _, err := ParseHealth("sick"); err == nil {
+		t.Fatalf("expected error for invalid health")
+	}
+}
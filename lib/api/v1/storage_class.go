@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+const (
+	// StorageClassKind is the kind of a single storage class resource
+	StorageClassKind = "StorageClass"
+
+	// StorageClassListKind is the kind of a collection of storage class
+	// resources
+	StorageClassListKind = "StorageClassList"
+)
+
+// StorageClass is a named profile of a VSM. A claim that names the class is
+// provisioned with the parameters of the class.
+type StorageClass struct {
+	mayav1.TypeMeta `json:",inline"`
+
+	mayav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Parameters are the volume provisioner & orchestrator labels that are
+	// set on the claims of the class
+	Parameters map[string]string `json:"parameters"`
+}
+
+// StorageClassList is a collection of storage classes
+type StorageClassList struct {
+	mayav1.TypeMeta `json:",inline"`
+
+	mayav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of storage classes
+	Items []StorageClass `json:"items"`
+}
+
+// NewStorageClass returns a storage class of the given name & parameters
+func NewStorageClass(name string, parameters map[string]string) *StorageClass {
+	sc := &StorageClass{Parameters: parameters}
+	sc.Kind = StorageClassKind
+	sc.APIVersion = APIVersion
+	sc.Name = name
+	return sc
+}
+
+// Validate validates the parameters of the storage class the same way as
+// the labels of a claim
+func (sc *StorageClass) Validate() error {
+	var result *multierror.Error
+
+	keys := make([]string, 0, len(sc.Parameters))
+	for k := range sc.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := validateLabel(k, sc.Parameters[k]); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if err := result.ErrorOrNil(); err != nil {
+		return fmt.Errorf("storage class '%s': %v", sc.Name, err)
+	}
+	return nil
+}
+
+// Apply sets the parameters of the storage class as the labels of the
+// claim. The labels that are set by the claim take precedence.
+func (sc *StorageClass) Apply(pvc *mayav1.PersistentVolumeClaim) {
+	if len(sc.Parameters) == 0 {
+		return
+	}
+	if pvc.Labels == nil {
+		pvc.Labels = map[string]string{}
+	}
+	for k, v := range sc.Parameters {
+		if _, ok := pvc.Labels[k]; !ok {
+			pvc.Labels[k] = v
+		}
+	}
+}
+
+// ClaimStorageClass returns the name of the storage class of the claim. It
+// is empty if the claim does not name a class.
+func ClaimStorageClass(pvc *mayav1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
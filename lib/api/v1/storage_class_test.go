@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"testing"
+
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+func TestStorageClassApply(t *testing.T) {
+	replicas := string(mayav1.PVPReplicaCountLbl)
+	image := string(mayav1.PVPReplicaImageLbl)
+
+	sc := NewStorageClass("fast", map[string]string{
+		replicas: "3",
+		image:    "openebs/jiva:0.4.0",
+	})
+	if err := sc.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	pvc := &mayav1.PersistentVolumeClaim{}
+	pvc.Labels = map[string]string{replicas: "2"}
+	name := "fast"
+	pvc.Spec.StorageClassName = &name
+
+	if ClaimStorageClass(pvc) != "fast" {
+		t.Fatalf("bad: expected the class of the claim")
+	}
+
+	// The labels of the claim take precedence
+	sc.Apply(pvc)
+	if pvc.Labels[replicas] != "2" || pvc.Labels[image] != "openebs/jiva:0.4.0" {
+		t.Fatalf("bad: %v", pvc.Labels)
+	}
+}
+
+func TestStorageClassValidate(t *testing.T) {
+	sc := NewStorageClass("bad", map[string]string{
+		string(mayav1.PVPReplicaCountLbl):     "20",
+		"volumeprovisioner.mapi.openebs.io/x": "y",
+	})
+	if err := sc.Validate(); err == nil {
+		t.Fatalf("expected the invalid parameters to be reported")
+	}
+}
@@ -0,0 +1,157 @@
+// Package v1 provides the typed resources exposed by maya api server.
+//
+// NOTE:
+//    These types replace the annotation maps that are set against
+// a PersistentVolume to describe a VSM.
+package v1
+
+import (
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+const (
+	// APIVersion is the versioned schema of the resources in this package
+	APIVersion = "openebs.io/v1"
+
+	// VSMKind is the kind of a single VSM resource
+	VSMKind = "VSM"
+
+	// VSMListKind is the kind of a collection of VSM resources
+	VSMListKind = "VSMList"
+)
+
+// VSM represents a Volume Storage Machine i.e. a persistent volume along with
+// its controllers & replicas
+type VSM struct {
+	mayav1.TypeMeta `json:",inline"`
+
+	mayav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired characteristics of the VSM
+	Spec VSMSpec `json:"spec"`
+
+	// Status represents the observed state of the VSM
+	Status VSMStatus `json:"status"`
+}
+
+// VSMList is a collection of VSMs
+type VSMList struct {
+	mayav1.TypeMeta `json:",inline"`
+
+	mayav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of VSMs
+	Items []VSM `json:"items"`
+
+	// Warnings has an entry for every region that could not be listed. It
+	// is set only when the VSMs of every region are listed.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// VSMSpec provides the desired characteristics of a VSM
+type VSMSpec struct {
+	// Capacity is the provisioned size of the VSM e.g. 1G
+	Capacity string `json:"capacity,omitempty"`
+
+	// CapacityBytes is the provisioned size of the VSM in bytes
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+
+	// ReplicaCount is the number of replicas desired for the VSM
+	ReplicaCount int `json:"replicaCount"`
+}
+
+// VSMStatus provides the observed state of a VSM
+type VSMStatus struct {
+	// Phase of the VSM's persistent volume
+	Phase mayav1.PersistentVolumePhase `json:"phase,omitempty"`
+
+	// Controllers of the VSM
+	Controllers []Controller `json:"controllers,omitempty"`
+
+	// Replicas of the VSM
+	Replicas []Replica `json:"replicas,omitempty"`
+
+	// Target is the iSCSI target exposed by the VSM
+	Target ISCSITarget `json:"target"`
+
+	// Health is the overall health of the VSM
+	Health VSMHealth `json:"health,omitempty"`
+
+	// Conditions are the latest observations of the VSM's state
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// Controller represents a controller of a VSM
+type Controller struct {
+	// IP is the address of the controller
+	IP string `json:"ip,omitempty"`
+
+	// Status of the controller e.g. Running, Pending
+	Status string `json:"status,omitempty"`
+}
+
+// Replica represents a replica of a VSM
+type Replica struct {
+	// IP is the address of the replica
+	IP string `json:"ip,omitempty"`
+
+	// Status of the replica e.g. Running, Pending
+	Status string `json:"status,omitempty"`
+
+	// Node where the replica is placed. This is set only when the
+	// orchestrator reports the placement via ReplicaNodesAPILbl.
+	Node string `json:"node,omitempty"`
+}
+
+// ISCSITarget provides the details to login to the VSM's iSCSI target
+type ISCSITarget struct {
+	// IQN is the iSCSI qualified name of the target
+	IQN string `json:"iqn,omitempty"`
+
+	// Portals are the ip:port pairs where the target is served
+	Portals []string `json:"portals,omitempty"`
+
+	// ClusterIPs are the service IPs of the controller(s)
+	ClusterIPs []string `json:"clusterIPs,omitempty"`
+}
+
+// ConditionType is a valid value for Condition.Type
+type ConditionType string
+
+const (
+	// ControllerReady is set when all the controllers of the VSM are running
+	ControllerReady ConditionType = "ControllerReady"
+
+	// ReplicasReady is set when all the desired replicas of the VSM are running
+	ReplicasReady ConditionType = "ReplicasReady"
+)
+
+// ConditionStatus is a valid value for Condition.Status
+type ConditionStatus string
+
+const (
+	// ConditionTrue means the VSM is in the condition
+	ConditionTrue ConditionStatus = "True"
+
+	// ConditionFalse means the VSM is not in the condition
+	ConditionFalse ConditionStatus = "False"
+
+	// ConditionUnknown means it can not be decided if the VSM is in the
+	// condition
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// Condition is an observation of a VSM's state
+type Condition struct {
+	// Type of the condition
+	Type ConditionType `json:"type"`
+
+	// Status of the condition i.e. one of True, False or Unknown
+	Status ConditionStatus `json:"status"`
+
+	// Reason is a brief CamelCase reason for the condition's status
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable detail of the condition's status
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	mayav1 "github.com/openebs/maya/types/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// MaxReplicaCount is the maximum number of replicas of a VSM
+	MaxReplicaCount = 10
+
+	// MaxControllerCount is the maximum number of controllers of a VSM
+	MaxControllerCount = 1
+)
+
+// labelPrefixes are the prefixes of the label set that is understood by
+// maya api server. A label with one of these prefixes must be a known label.
+var labelPrefixes = []string{
+	"volumeprovisioner.mapi.openebs.io/",
+	"orchprovider.mapi.openebs.io/",
+	"env.mapi.openebs.io/",
+}
+
+// labelValidator validates the value of a single label & returns the reason
+// if the value is invalid
+type labelValidator func(value string) string
+
+// labelValidators has a validator for every label of the v1 label set
+var labelValidators = map[string]labelValidator{
+	string(mayav1.PVPProfileNameLbl):        oneOf(string(mayav1.PVCProvisionerProfile)),
+	string(mayav1.PVPReqReplicaLbl):         isBool,
+	string(mayav1.PVPReqNetworkingLbl):      isBool,
+	string(mayav1.PVPReplicaCountLbl):       inRange(1, MaxReplicaCount),
+	string(mayav1.PVPStorageSizeLbl):        isSize,
+	string(mayav1.PVPReplicaIPsLbl):         isIPList,
+	string(mayav1.PVPReplicaImageLbl):       isImage,
+	string(mayav1.PVPControllerCountLbl):    inRange(1, MaxControllerCount),
+	string(mayav1.PVPControllerImageLbl):    isImage,
+	string(mayav1.PVPControllerIPsLbl):      isIPList,
+	string(mayav1.PVPPersistentPathLbl):     isAbsPath,
+	string(mayav1.PVPReplicaTopologyKeyLbl): isQualifiedName,
+	string(mayav1.VolumeProvisionerNameLbl): oneOf(string(mayav1.JivaVolumeProvisioner)),
+
+	string(mayav1.OrchProfileNameLbl):   oneOf(string(mayav1.PVCOrchestratorProfile)),
+	string(mayav1.OrchRegionLbl):        isNotEmpty,
+	string(mayav1.OrchDCLbl):            isNotEmpty,
+	string(mayav1.OrchAddrLbl):          isHost,
+	string(mayav1.OrchNSLbl):            isDNSLabel,
+	string(mayav1.OrchInClusterLbl):     isBool,
+	string(mayav1.OrchCNTypeLbl):        isNotEmpty,
+	string(mayav1.OrchCNNetworkAddrLbl): isCIDR,
+	string(mayav1.OrchCNSubnetLbl):      inRange(0, 32),
+	string(mayav1.OrchCNInterfaceLbl):   isNotEmpty,
+	string(mayav1.OrchestratorNameLbl):  oneOf(string(mayav1.K8sOrchestrator), string(mayav1.NomadOrchestrator)),
+
+	string(mayav1.EnvVariableContextLbl): isNotEmpty,
+}
+
+// ValidateClaim validates the name & the labels of the claim before a VSM is
+// created out of it. Every violation is returned at once.
+func ValidateClaim(pvc *mayav1.PersistentVolumeClaim) error {
+	var result *multierror.Error
+
+	if pvc.Name == "" {
+		result = multierror.Append(result, fmt.Errorf("name: VSM name is missing"))
+	} else if msgs := validation.IsDNS1123Label(pvc.Name); len(msgs) != 0 {
+		result = multierror.Append(result, fmt.Errorf("name: invalid value '%s': %s", pvc.Name, strings.Join(msgs, ", ")))
+	}
+
+	keys := make([]string, 0, len(pvc.Labels))
+	for k := range pvc.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := validateLabel(k, pvc.Labels[k]); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// validateLabel validates a single label. Labels outside the v1 label set
+// are not validated.
+func validateLabel(key, value string) error {
+	validator, ok := labelValidators[key]
+	if !ok {
+		for _, prefix := range labelPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				return fmt.Errorf("%s: unknown label", key)
+			}
+		}
+		return nil
+	}
+
+	if reason := validator(strings.TrimSpace(value)); reason != "" {
+		return fmt.Errorf("%s: invalid value '%s': %s", key, value, reason)
+	}
+	return nil
+}
+
+func oneOf(allowed ...string) labelValidator {
+	return func(value string) string {
+		for _, a := range allowed {
+			if value == a {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))
+	}
+}
+
+func inRange(min, max int) labelValidator {
+	return func(value string) string {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "must be an integer"
+		}
+		if n < min || n > max {
+			return fmt.Sprintf("must be between %d and %d", min, max)
+		}
+		return ""
+	}
+}
+
+func isNotEmpty(value string) string {
+	if value == "" {
+		return "must not be empty"
+	}
+	return ""
+}
+
+func isBool(value string) string {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return "must be true or false"
+	}
+	return ""
+}
+
+func isSize(value string) string {
+	q, err := mayav1.ParseQuantity(value)
+	if err != nil {
+		return "must be a size e.g. 1G"
+	}
+	if q.Value() <= 0 {
+		return "must be greater than zero"
+	}
+	return ""
+}
+
+func isIPList(value string) string {
+	for _, ip := range strings.Split(value, ",") {
+		if net.ParseIP(strings.TrimSpace(ip)) == nil {
+			return "must be a comma separated list of IP addresses"
+		}
+	}
+	return ""
+}
+
+func isImage(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n") {
+		return "must be a container image e.g. openebs/jiva:0.3-RC2"
+	}
+	return ""
+}
+
+func isAbsPath(value string) string {
+	if !path.IsAbs(value) {
+		return "must be an absolute path"
+	}
+	return ""
+}
+
+func isQualifiedName(value string) string {
+	return strings.Join(validation.IsQualifiedName(value), ", ")
+}
+
+func isDNSLabel(value string) string {
+	return strings.Join(validation.IsDNS1123Label(value), ", ")
+}
+
+func isHost(value string) string {
+	if net.ParseIP(value) != nil {
+		return ""
+	}
+	if msgs := validation.IsDNS1123Subdomain(value); len(msgs) != 0 {
+		return "must be an IP address or a host name"
+	}
+	return ""
+}
+
+func isCIDR(value string) string {
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		return "must be in CIDR notation e.g. 172.28.128.1/24"
+	}
+	return ""
+}
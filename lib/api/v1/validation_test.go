@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+func TestValidateClaim(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Labels     map[string]string
+		Violations []string
+	}{
+		{
+			"vol-1",
+			map[string]string{
+				"volumeprovisioner.mapi.openebs.io/replica-count": "3",
+				"volumeprovisioner.mapi.openebs.io/storage-size":  "10G",
+				"orchprovider.mapi.openebs.io/ns":                 "default",
+				"app":                                             "mysql",
+			},
+			nil,
+		},
+		{
+			"Vol_1",
+			map[string]string{
+				"volumeprovisioner.mapi.openebs.io/replica-count": "abc",
+				"volumeprovisioner.mapi.openebs.io/storage-size":  "10 gigs",
+				"volumeprovisioner.mapi.openebs.io/replicas":      "2",
+				"orchprovider.mapi.openebs.io/name":               "swarm",
+			},
+			[]string{
+				"name: invalid value 'Vol_1'",
+				"orchprovider.mapi.openebs.io/name: invalid value 'swarm'",
+				"volumeprovisioner.mapi.openebs.io/replica-count: invalid value 'abc': must be an integer",
+				"volumeprovisioner.mapi.openebs.io/replicas: unknown label",
+				"volumeprovisioner.mapi.openebs.io/storage-size: invalid value '10 gigs'",
+			},
+		},
+		{
+			"vol-2",
+			map[string]string{
+				"volumeprovisioner.mapi.openebs.io/replica-count":   "11",
+				"volumeprovisioner.mapi.openebs.io/persistent-path": "var/openebs",
+				"volumeprovisioner.mapi.openebs.io/replica-ips":     "10.0.0.1,10.0.0",
+			},
+			[]string{
+				"persistent-path: invalid value 'var/openebs'",
+				"replica-count: invalid value '11': must be between 1 and 10",
+				"replica-ips: invalid value '10.0.0.1,10.0.0'",
+			},
+		},
+		{
+			"",
+			nil,
+			[]string{"name: VSM name is missing"},
+		},
+	}
+
+	for _, c := range cases {
+		pvc := &mayav1.PersistentVolumeClaim{}
+		pvc.Name = c.Name
+		pvc.Labels = c.Labels
+
+		err := ValidateClaim(pvc)
+		if len(c.Violations) == 0 {
+			if err != nil {
+				t.Fatalf("ERR: %s: expected no violations, got: %v", c.Name, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Fatalf("ERR: %s: expected violations: %v", c.Name, c.Violations)
+		}
+
+		for _, v := range c.Violations {
+			if !strings.Contains(err.Error(), v) {
+				t.Fatalf("ERR: %s: expected violation: %s, got: %v", c.Name, v, err)
+			}
+		}
+
+		if n := strings.Count(err.Error(), "\n* "); n != len(c.Violations) {
+			t.Fatalf("ERR: %s: expected %d violations, got: %v", c.Name, len(c.Violations), err)
+		}
+	}
+}
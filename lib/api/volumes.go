@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/url"
+
+	mayav1 "github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+// volumesPath is the path of the typed VSM resources
+const volumesPath = "/v2/volumes/"
+
+// Volumes is used to deal with the VSMs
+type Volumes struct {
+	client *Client
+}
+
+// Volumes returns a handle on the VSMs
+func (c *Client) Volumes() *Volumes {
+	return &Volumes{client: c}
+}
+
+// List lists the VSMs. It is a blocking query if q sets a WaitIndex.
+func (v *Volumes) List(q *QueryOptions) (*mapiv1.VSMList, *QueryMeta, error) {
+	l := &mapiv1.VSMList{}
+	qm, err := v.client.query(volumesPath, l, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, qm, nil
+}
+
+// Info fetches a single VSM. It is a blocking query if q sets a WaitIndex.
+func (v *Volumes) Info(name string, q *QueryOptions) (*mapiv1.VSM, *QueryMeta, error) {
+	vsm := &mapiv1.VSM{}
+	qm, err := v.client.query(volumesPath+url.PathEscape(name), vsm, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vsm, qm, nil
+}
+
+// Create provisions a VSM out of the claim
+func (v *Volumes) Create(pvc *mayav1.PersistentVolumeClaim, w *WriteOptions) (*mapiv1.VSM, error) {
+	vsm := &mapiv1.VSM{}
+	if err := v.client.write("POST", volumesPath, pvc, vsm, w); err != nil {
+		return nil, err
+	}
+	return vsm, nil
+}
+
+// Delete deletes a VSM
+func (v *Volumes) Delete(name string, w *WriteOptions) error {
+	return v.client.write("DELETE", volumesPath+url.PathEscape(name), nil, nil, w)
+}
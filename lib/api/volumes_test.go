@@ -0,0 +1,37 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+func TestVolumes(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Cleanup()
+
+	// An invalid claim is rejected before the orchestrator is invoked
+	pvc := &mayav1.PersistentVolumeClaim{}
+	pvc.Name = "vol1"
+	pvc.Labels = map[string]string{string(mayav1.PVPReplicaCountLbl): "zero"}
+
+	_, err := c.Volumes().Create(pvc, nil)
+	if e, ok := err.(*UnexpectedResponseError); !ok || e.StatusCode != 422 {
+		t.Fatalf("ERR: expected a 422 response, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), string(mayav1.PVPReplicaCountLbl)) {
+		t.Fatalf("ERR: expected the invalid label in the error: %v", err)
+	}
+
+	// There is no orchestrator in the test server
+	if _, _, err := c.Volumes().List(nil); err == nil {
+		t.Fatalf("ERR: expected an error without an orchestrator")
+	}
+	if _, _, err := c.Volumes().Info("vol1", nil); err == nil {
+		t.Fatalf("ERR: expected an error without an orchestrator")
+	}
+	if err := c.Volumes().Delete("vol1", nil); err == nil {
+		t.Fatalf("ERR: expected an error without an orchestrator")
+	}
+}
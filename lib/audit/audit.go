@@ -0,0 +1,266 @@
+// Package audit records the mutating API calls of maya api server as an
+// append only log of JSON lines.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// FileName is the name of the current audit log file. The rotated files
+	// are suffixed with their generation e.g. audit.log.1 is the most recent.
+	FileName = "audit.log"
+
+	// DefaultMaxBytes is the size after which the audit log is rotated
+	DefaultMaxBytes = 10 << 20
+
+	// DefaultMaxFiles is the number of rotated audit log files that are
+	// retained
+	DefaultMaxFiles = 5
+)
+
+// Event is a single audited API call
+type Event struct {
+	Time      time.Time `json:"time"`
+	Principal string    `json:"principal"`
+	ClientIP  string    `json:"clientIP"`
+	RequestID string    `json:"requestID"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Volume    string    `json:"volume,omitempty"`
+
+	// Action is the operation of a request that is not identified by its
+	// method & path e.g. the Action of an EC2 Query API call
+	Action string `json:"action,omitempty"`
+
+	// BodyDigest is the digest of the request body. The body itself is
+	// never recorded.
+	BodyDigest string `json:"bodyDigest,omitempty"`
+
+	// Code is the http status code of the response
+	Code int `json:"code"`
+
+	// Outcome is either success or failure
+	Outcome string `json:"outcome"`
+}
+
+// Query filters the audited events. The zero value matches every event.
+type Query struct {
+	Volume string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Matches returns true if the event passes the filters of the query
+func (q Query) Matches(e *Event) bool {
+	if q.Volume != "" && q.Volume != e.Volume {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+// Log is an append only audit log that is rotated by size
+type Log struct {
+	dir      string
+	maxBytes int64
+	maxFiles int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewLog opens the audit log in the given directory. The defaults are used
+// if maxBytes or maxFiles are not positive.
+func NewLog(dir string, maxBytes int64, maxFiles int) (*Log, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit dir: %v", err)
+	}
+
+	l := &Log{
+		dir:      dir,
+		maxBytes: maxBytes,
+		maxFiles: maxFiles,
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open opens the current audit log file for appending
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path(0), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.file = f
+	l.size = fi.Size()
+	return nil
+}
+
+// path returns the path of the audit log file of the given generation. The
+// current file is generation 0.
+func (l *Log) path(gen int) string {
+	if gen == 0 {
+		return filepath.Join(l.dir, FileName)
+	}
+	return filepath.Join(l.dir, fmt.Sprintf("%s.%d", FileName, gen))
+}
+
+// Write appends the event to the audit log. The log is rotated before the
+// event if the event would exceed the maximum size.
+func (l *Log) Write(e *Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+
+	if l.size > 0 && l.size+int64(len(b)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(b)
+	l.size += int64(n)
+	return err
+}
+
+// rotate shifts every file by a generation & starts a new current file. The
+// file of the oldest generation is removed.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	os.Remove(l.path(l.maxFiles))
+	for gen := l.maxFiles - 1; gen >= 0; gen-- {
+		if err := os.Rename(l.path(gen), l.path(gen+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return l.open()
+}
+
+// Query returns the events that match the query, oldest first. The files are
+// read without holding the lock so that the writes are not blocked.
+func (l *Log) Query(q Query) ([]*Event, error) {
+	files, err := l.openFiles()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	events := []*Event{}
+	for _, f := range files {
+		matched, err := readEvents(f, q)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, matched...)
+	}
+
+	return events, nil
+}
+
+// openFiles opens every generation of the audit log, oldest first. The
+// files are opened with the lock held so that a rotation does not shift
+// them in between. An open file can be read even after it is rotated.
+func (l *Log) openFiles() ([]*os.File, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var files []*os.File
+	for gen := l.maxFiles; gen >= 0; gen-- {
+		f, err := os.Open(l.path(gen))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// readEvents reads the events of a single audit log file that match the
+// query
+func readEvents(r io.Reader, q Query) ([]*Event, error) {
+	var events []*Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		e := &Event{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			// A partially written line is skipped
+			continue
+		}
+		if q.Matches(e) {
+			events = append(events, e)
+		}
+	}
+
+	return events, scanner.Err()
+}
+
+// Close closes the audit log. Further writes fail.
+func (l *Log) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
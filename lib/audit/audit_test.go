@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLogWriteQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	l, err := NewLog(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer l.Close()
+
+	now := time.Now()
+	events := []*Event{
+		{Time: now.Add(-2 * time.Hour), Method: "POST", Volume: "vol1", Code: 200, Outcome: "success"},
+		{Time: now.Add(-1 * time.Hour), Method: "POST", Volume: "vol2", Code: 200, Outcome: "success"},
+		{Time: now, Method: "DELETE", Volume: "vol1", Code: 412, Outcome: "failure"},
+	}
+	for _, e := range events {
+		if err := l.Write(e); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	cases := []struct {
+		q        Query
+		expected int
+	}{
+		{Query{}, 3},
+		{Query{Volume: "vol1"}, 2},
+		{Query{Since: now.Add(-90 * time.Minute)}, 2},
+		{Query{Volume: "vol1", Until: now.Add(-90 * time.Minute)}, 1},
+	}
+
+	for _, c := range cases {
+		actual, err := l.Query(c.q)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if len(actual) != c.expected {
+			t.Fatalf("bad: %+v: expected %d events, got: %d", c.q, c.expected, len(actual))
+		}
+	}
+}
+
+func TestLogRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Every event is rotated into its own file
+	l, err := NewLog(dir, 10, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer l.Close()
+
+	for _, v := range []string{"vol1", "vol2", "vol3", "vol4"} {
+		if err := l.Write(&Event{Time: time.Now(), Volume: v}); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	for _, gen := range []int{0, 1, 2} {
+		if _, err := os.Stat(l.path(gen)); err != nil {
+			t.Fatalf("bad: expected generation %d, got: %v", gen, err)
+		}
+	}
+	if _, err := os.Stat(l.path(3)); !os.IsNotExist(err) {
+		t.Fatalf("bad: expected generation 3 to be removed")
+	}
+
+	// The oldest event is rotated out & the rest are returned oldest first
+	events, err := l.Query(Query{})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(events) != 3 || events[0].Volume != "vol2" || events[2].Volume != "vol4" {
+		t.Fatalf("bad: %+v", events)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // MayaConfig is the configuration for Maya server.
@@ -82,18 +83,63 @@ type MayaConfig struct {
 	// HTTPAPIResponseHeaders allows users to configure the Nomad http agent to
 	// set arbritrary headers on API responses
 	HTTPAPIResponseHeaders map[string]string `mapstructure:"http_api_response_headers"`
+
+	// LatestAPIVersion is the API version that is served at /latest i.e.
+	// either v1 or v2. Defaults to v1.
+	LatestAPIVersion string `mapstructure:"latest_api_version"`
+
+	// MaxRequestBodySize is the maximum size of a request body in bytes.
+	// Defaults to DefaultMaxRequestBodySize.
+	MaxRequestBodySize int64 `mapstructure:"max_request_body_size"`
+
+	// StrictDecoding rejects a request body that has a field which is not
+	// known to the API
+	StrictDecoding bool `mapstructure:"strict_decoding"`
+
+	// Metadata is used to configure the EC2 metadata style identity that is
+	// served at /latest/meta-data
+	Metadata *Metadata `mapstructure:"metadata"`
+
+	// ACL is used to control the access to the management endpoints
+	ACL *ACL `mapstructure:"acl"`
+
+	// Audit is used to record the mutating API calls
+	Audit *Audit `mapstructure:"audit"`
+
+	// Peers are the maya servers of other regions. Requests for a region
+	// other than Region are forwarded to these.
+	Peers []*Peer `mapstructure:"peer"`
+
+	// Webhooks are notified of the volume lifecycle events
+	Webhooks []*Webhook `mapstructure:"webhook"`
+
+	// Quotas limit the VSMs that may be provisioned per namespace
+	Quotas []*Quota `mapstructure:"quota"`
+
+	// Admissions are the policies that admit a claim before its VSM is
+	// provisioned. These run in the order they are configured.
+	Admissions []*Admission `mapstructure:"admission"`
+
+	// StorageClasses are the named profiles of the VSMs. A claim that names
+	// a class is provisioned with its settings.
+	StorageClasses []*StorageClass `mapstructure:"storage_class"`
 }
 
 // Ports encapsulates the various ports we bind to for network services. If any
 // are not specified then the defaults are used instead.
 type Ports struct {
 	HTTP int `mapstructure:"http"`
+
+	// Admin is the port of the separate listener that serves the debug
+	// endpoints. The debug endpoints are served over HTTP if not specified.
+	Admin int `mapstructure:"admin"`
 }
 
 // Addresses encapsulates all of the addresses we bind to for various
 // network services. Everything is optional and defaults to BindAddr.
 type Addresses struct {
-	HTTP string `mapstructure:"http"`
+	HTTP  string `mapstructure:"http"`
+	Admin string `mapstructure:"admin"`
 }
 
 // AdvertiseAddrs is used to control the addresses we advertise out for
@@ -103,6 +149,172 @@ type AdvertiseAddrs struct {
 	HTTP string `mapstructure:"http"`
 }
 
+// Metadata encapsulates the identity that is served in the EC2 metadata
+// style. Everything is optional & is derived from the rest of the config if
+// not specified.
+type Metadata struct {
+	// InstanceID is the id of the instance. Defaults to any-compute.
+	InstanceID string `mapstructure:"instance_id"`
+
+	// AvailabilityZone is the zone of the instance. Defaults to
+	// <region>-<datacenter>.
+	AvailabilityZone string `mapstructure:"availability_zone"`
+
+	// LocalIPv4 is the private IPv4 address of the instance. Defaults to the
+	// advertised HTTP address.
+	LocalIPv4 string `mapstructure:"local_ipv4"`
+
+	// Hostname is the hostname of the instance. Defaults to the node name
+	// & then to the hostname of the machine.
+	Hostname string `mapstructure:"hostname"`
+
+	// RequireToken rejects metadata requests that do not carry a session
+	// token issued via PUT /latest/api/token. This matches IMDSv2.
+	RequireToken bool `mapstructure:"require_token"`
+}
+
+// ACL encapsulates the access control of the management endpoints
+type ACL struct {
+	// Enabled turns on the access control
+	Enabled bool `mapstructure:"enabled"`
+
+	// ManagementToken is the token that is expected in the X-Maya-Token
+	// header of the management requests
+	ManagementToken string `mapstructure:"management_token"`
+}
+
+// Audit encapsulates the audit log of the mutating API calls
+type Audit struct {
+	// Enabled turns on the audit log
+	Enabled bool `mapstructure:"enabled"`
+
+	// Path is the directory of the audit log. Defaults to the audit
+	// directory under DataDir.
+	Path string `mapstructure:"path"`
+
+	// MaxBytes is the size after which the audit log is rotated
+	MaxBytes int64 `mapstructure:"max_bytes"`
+
+	// MaxFiles is the number of rotated audit log files that are retained
+	MaxFiles int `mapstructure:"max_files"`
+}
+
+// DefaultPeerTimeout is the timeout of a request forwarded to a peer if
+// the peer does not specify one
+const DefaultPeerTimeout = 10 * time.Second
+
+// Peer encapsulates the maya servers of a region
+type Peer struct {
+	// Region served by the peer. This is the key of the peer block.
+	Region string `mapstructure:"-"`
+
+	// Datacenter of the peer
+	Datacenter string `mapstructure:"datacenter"`
+
+	// Addresses are the host:port or URLs of the maya servers of the region.
+	// These are tried in order till one of them is reachable.
+	Addresses []string `mapstructure:"addresses"`
+
+	// Timeout of a forwarded request e.g. 10s
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Webhook encapsulates a HTTP endpoint that is notified of the volume
+// lifecycle events
+type Webhook struct {
+	// Name of the webhook. This is the key of the webhook block.
+	Name string `mapstructure:"-"`
+
+	// URL the events are posted to
+	URL string `mapstructure:"url"`
+
+	// Events are the subscribed event types e.g. volume.created. Every
+	// event is subscribed if there are none.
+	Events []string `mapstructure:"events"`
+
+	// Secret is the HMAC key the payloads are signed with
+	Secret string `mapstructure:"secret"`
+}
+
+// Quota encapsulates the limits of the VSMs of a namespace. A limit that is
+// not set is not enforced.
+type Quota struct {
+	// Namespace the quota applies to. This is the key of the quota block.
+	Namespace string `mapstructure:"-"`
+
+	// MaxVolumes is the maximum number of VSMs
+	MaxVolumes int `mapstructure:"max_volumes"`
+
+	// MaxCapacity is the maximum total capacity of the VSMs e.g. 100G
+	MaxCapacity string `mapstructure:"max_capacity"`
+
+	// MaxReplicas is the maximum number of replicas of a single VSM
+	MaxReplicas int `mapstructure:"max_replicas"`
+}
+
+// Admission encapsulates a policy that mutates or validates a claim before
+// its VSM is provisioned. A policy either sets labels, checks rules or calls
+// an external admission webhook.
+type Admission struct {
+	// Name of the policy. This is the key of the admission block.
+	Name string `mapstructure:"-"`
+
+	// Type is either mutating or validating
+	Type string `mapstructure:"type"`
+
+	// Namespaces & Names are the glob patterns of the claims the policy
+	// applies to e.g. db-*. The policy applies to every claim if these are
+	// not set.
+	Namespaces []string `mapstructure:"namespaces"`
+	Names      []string `mapstructure:"names"`
+
+	// Labels are set by a mutating policy. Overwrite replaces the labels
+	// that are set by the claim.
+	Labels    map[string]string `mapstructure:"-"`
+	Overwrite bool              `mapstructure:"overwrite"`
+
+	// Forbid are the label values that a validating policy denies & Require
+	// are the labels it requires. Message is the reason of a denial.
+	Forbid  map[string][]string `mapstructure:"-"`
+	Require []string            `mapstructure:"require"`
+	Message string              `mapstructure:"message"`
+
+	// URL of the external admission webhook
+	URL string `mapstructure:"url"`
+
+	// Timeout of a call to the webhook e.g. 5s
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// FailurePolicy is either fail or ignore. It decides if the claim fails
+	// when the webhook can not be reached.
+	FailurePolicy string `mapstructure:"failure_policy"`
+}
+
+// StorageClass encapsulates a named profile of the VSMs. A setting that is
+// not set falls back to the defaults of the volume provisioner.
+type StorageClass struct {
+	// Name of the class. This is the key of the storage_class block.
+	Name string `mapstructure:"-"`
+
+	ReplicaCount       int    `mapstructure:"replica_count"`
+	ReplicaImage       string `mapstructure:"replica_image"`
+	ReplicaTopologyKey string `mapstructure:"replica_topology_key"`
+	ControllerImage    string `mapstructure:"controller_image"`
+	PersistentPath     string `mapstructure:"persistent_path"`
+	StorageSize        string `mapstructure:"storage_size"`
+
+	// Orchestrator is the name of the orchestration provider e.g.
+	// kubernetes
+	Orchestrator string `mapstructure:"orchestrator"`
+
+	// Namespace of the orchestrator the VSMs are provisioned in
+	Namespace string `mapstructure:"namespace"`
+}
+
+// DefaultMaxRequestBodySize is the maximum size of a request body in bytes
+// if not configured
+const DefaultMaxRequestBodySize = 1 << 20
+
 // DefaultMayaConfig is a the baseline configuration for Maya server
 func DefaultMayaConfig() *MayaConfig {
 	return &MayaConfig{
@@ -113,9 +325,14 @@ func DefaultMayaConfig() *MayaConfig {
 		Ports: &Ports{
 			HTTP: 5656,
 		},
-		Addresses:      &Addresses{},
-		AdvertiseAddrs: &AdvertiseAddrs{},
-		SyslogFacility: "LOCAL0",
+		Addresses:          &Addresses{},
+		AdvertiseAddrs:     &AdvertiseAddrs{},
+		SyslogFacility:     "LOCAL0",
+		LatestAPIVersion:   "v1",
+		MaxRequestBodySize: DefaultMaxRequestBodySize,
+		Metadata:           &Metadata{},
+		ACL:                &ACL{},
+		Audit:              &Audit{},
 	}
 }
 
@@ -181,6 +398,15 @@ func (mc *MayaConfig) Merge(b *MayaConfig) *MayaConfig {
 	if b.SyslogFacility != "" {
 		result.SyslogFacility = b.SyslogFacility
 	}
+	if b.LatestAPIVersion != "" {
+		result.LatestAPIVersion = b.LatestAPIVersion
+	}
+	if b.MaxRequestBodySize != 0 {
+		result.MaxRequestBodySize = b.MaxRequestBodySize
+	}
+	if b.StrictDecoding {
+		result.StrictDecoding = true
+	}
 
 	// Apply the ports config
 	if result.Ports == nil && b.Ports != nil {
@@ -206,6 +432,111 @@ func (mc *MayaConfig) Merge(b *MayaConfig) *MayaConfig {
 		result.AdvertiseAddrs = result.AdvertiseAddrs.Merge(b.AdvertiseAddrs)
 	}
 
+	// Apply the metadata config
+	if result.Metadata == nil && b.Metadata != nil {
+		metadata := *b.Metadata
+		result.Metadata = &metadata
+	} else if b.Metadata != nil {
+		result.Metadata = result.Metadata.Merge(b.Metadata)
+	}
+
+	// Apply the acl config
+	if result.ACL == nil && b.ACL != nil {
+		acl := *b.ACL
+		result.ACL = &acl
+	} else if b.ACL != nil {
+		result.ACL = result.ACL.Merge(b.ACL)
+	}
+
+	// Apply the audit config
+	if result.Audit == nil && b.Audit != nil {
+		audit := *b.Audit
+		result.Audit = &audit
+	} else if b.Audit != nil {
+		result.Audit = result.Audit.Merge(b.Audit)
+	}
+
+	// Apply the peers config. A peer of the same region is replaced.
+	if len(b.Peers) > 0 {
+		peers := make([]*Peer, 0, len(result.Peers)+len(b.Peers))
+		for _, p := range result.Peers {
+			if b.Peer(p.Region) == nil {
+				peers = append(peers, p)
+			}
+		}
+		for _, p := range b.Peers {
+			peer := *p
+			peers = append(peers, &peer)
+		}
+		result.Peers = peers
+	}
+
+	// Apply the webhooks config. A webhook of the same name is replaced.
+	if len(b.Webhooks) > 0 {
+		hooks := make([]*Webhook, 0, len(result.Webhooks)+len(b.Webhooks))
+		for _, w := range result.Webhooks {
+			if b.Webhook(w.Name) == nil {
+				hooks = append(hooks, w)
+			}
+		}
+		for _, w := range b.Webhooks {
+			hook := *w
+			hooks = append(hooks, &hook)
+		}
+		result.Webhooks = hooks
+	}
+
+	// Apply the quotas config. A quota of the same namespace is replaced.
+	if len(b.Quotas) > 0 {
+		quotas := make([]*Quota, 0, len(result.Quotas)+len(b.Quotas))
+		for _, q := range result.Quotas {
+			if b.Quota(q.Namespace) == nil {
+				quotas = append(quotas, q)
+			}
+		}
+		for _, q := range b.Quotas {
+			quota := *q
+			quotas = append(quotas, &quota)
+		}
+		result.Quotas = quotas
+	}
+
+	// Apply the admissions config. A policy of the same name is replaced in
+	// place, so the order of the policies is retained.
+	if len(b.Admissions) > 0 {
+		admissions := make([]*Admission, 0, len(result.Admissions)+len(b.Admissions))
+		for _, a := range result.Admissions {
+			if override := b.Admission(a.Name); override != nil {
+				a = override
+			}
+			admission := *a
+			admissions = append(admissions, &admission)
+		}
+		for _, a := range b.Admissions {
+			if result.Admission(a.Name) == nil {
+				admission := *a
+				admissions = append(admissions, &admission)
+			}
+		}
+		result.Admissions = admissions
+	}
+
+	// Apply the storage classes config. A class of the same name is
+	// replaced.
+	if len(b.StorageClasses) > 0 {
+		classes := make([]*StorageClass, 0, len(result.StorageClasses)+len(b.StorageClasses))
+		for _, sc := range result.StorageClasses {
+			if b.StorageClass(sc.Name) == nil {
+				classes = append(classes, sc)
+			}
+		}
+		for _, sc := range b.StorageClasses {
+			class := *sc
+			classes = append(classes, &class)
+		}
+		result.StorageClasses = classes
+	}
+
 	// Merge config files lists
 	result.Files = append(result.Files, b.Files...)
 
@@ -228,6 +559,11 @@ func (mc *MayaConfig) NormalizeAddrs() error {
 		HTTP: net.JoinHostPort(mc.Addresses.HTTP, strconv.Itoa(mc.Ports.HTTP)),
 	}
 
+	if mc.Ports.Admin != 0 {
+		mc.Addresses.Admin = normalizeBind(mc.Addresses.Admin, mc.BindAddr)
+		mc.NormalizedAddrs.Admin = net.JoinHostPort(mc.Addresses.Admin, strconv.Itoa(mc.Ports.Admin))
+	}
+
 	addr, err := normalizeAdvertise(mc.AdvertiseAddrs.HTTP, mc.Addresses.HTTP, mc.Ports.HTTP)
 	if err != nil {
 		return fmt.Errorf("Failed to parse HTTP advertise address: %v", err)
@@ -331,6 +667,9 @@ func (a *Ports) Merge(b *Ports) *Ports {
 	if b.HTTP != 0 {
 		result.HTTP = b.HTTP
 	}
+	if b.Admin != 0 {
+		result.Admin = b.Admin
+	}
 	return &result
 }
 
@@ -341,6 +680,9 @@ func (a *Addresses) Merge(b *Addresses) *Addresses {
 	if b.HTTP != "" {
 		result.HTTP = b.HTTP
 	}
+	if b.Admin != "" {
+		result.Admin = b.Admin
+	}
 	return &result
 }
 
@@ -354,6 +696,110 @@ func (a *AdvertiseAddrs) Merge(b *AdvertiseAddrs) *AdvertiseAddrs {
 	return &result
 }
 
+// Merge merges two metadata configs together.
+func (a *Metadata) Merge(b *Metadata) *Metadata {
+	result := *a
+
+	if b.InstanceID != "" {
+		result.InstanceID = b.InstanceID
+	}
+	if b.AvailabilityZone != "" {
+		result.AvailabilityZone = b.AvailabilityZone
+	}
+	if b.LocalIPv4 != "" {
+		result.LocalIPv4 = b.LocalIPv4
+	}
+	if b.Hostname != "" {
+		result.Hostname = b.Hostname
+	}
+	if b.RequireToken {
+		result.RequireToken = true
+	}
+	return &result
+}
+
+// Peer returns the peer of the given region if configured
+func (mc *MayaConfig) Peer(region string) *Peer {
+	for _, p := range mc.Peers {
+		if p.Region == region {
+			return p
+		}
+	}
+	return nil
+}
+
+// Webhook returns the webhook of the given name if configured
+func (mc *MayaConfig) Webhook(name string) *Webhook {
+	for _, w := range mc.Webhooks {
+		if w.Name == name {
+			return w
+		}
+	}
+	return nil
+}
+
+// Quota returns the quota of the given namespace if configured
+func (mc *MayaConfig) Quota(namespace string) *Quota {
+	for _, q := range mc.Quotas {
+		if q.Namespace == namespace {
+			return q
+		}
+	}
+	return nil
+}
+
+// Admission returns the admission policy of the given name if configured
+func (mc *MayaConfig) Admission(name string) *Admission {
+	for _, a := range mc.Admissions {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// StorageClass returns the storage class of the given name if configured
+func (mc *MayaConfig) StorageClass(name string) *StorageClass {
+	for _, sc := range mc.StorageClasses {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+// Merge merges two acl configs together.
+func (a *ACL) Merge(b *ACL) *ACL {
+	result := *a
+
+	if b.Enabled {
+		result.Enabled = true
+	}
+	if b.ManagementToken != "" {
+		result.ManagementToken = b.ManagementToken
+	}
+	return &result
+}
+
+// Merge is used to merge two audit configs together
+func (a *Audit) Merge(b *Audit) *Audit {
+	result := *a
+
+	if b.Enabled {
+		result.Enabled = true
+	}
+	if b.Path != "" {
+		result.Path = b.Path
+	}
+	if b.MaxBytes != 0 {
+		result.MaxBytes = b.MaxBytes
+	}
+	if b.MaxFiles != 0 {
+		result.MaxFiles = b.MaxFiles
+	}
+	return &result
+}
+
 // LoadMayaConfig loads the configuration at the given path, regardless if
 // its a file or directory.
 func LoadMayaConfig(path string) (*MayaConfig, error) {
@@ -85,6 +85,17 @@ func parseConfig(result *MayaConfig, list *ast.ObjectList) error {
 		"enable_syslog",
 		"syslog_facility",
 		"http_api_response_headers",
+		"latest_api_version",
+		"max_request_body_size",
+		"strict_decoding",
+		"metadata",
+		"acl",
+		"audit",
+		"peer",
+		"webhook",
+		"quota",
+		"admission",
+		"storage_class",
 	}
 	if err := checkHCLKeys(list, valid); err != nil {
 		return multierror.Prefix(err, "config:")
@@ -100,6 +111,14 @@ func parseConfig(result *MayaConfig, list *ast.ObjectList) error {
 	delete(m, "interfaces")
 	delete(m, "advertise")
 	delete(m, "http_api_response_headers")
+	delete(m, "metadata")
+	delete(m, "acl")
+	delete(m, "audit")
+	delete(m, "peer")
+	delete(m, "webhook")
+	delete(m, "quota")
+	delete(m, "admission")
+	delete(m, "storage_class")
 
 	// Decode the rest
 	if err := mapstructure.WeakDecode(m, result); err != nil {
@@ -127,6 +146,62 @@ func parseConfig(result *MayaConfig, list *ast.ObjectList) error {
 		}
 	}
 
+	// Parse metadata
+	if o := list.Filter("metadata"); len(o.Items) > 0 {
+		if err := parseMetadata(&result.Metadata, o); err != nil {
+			return multierror.Prefix(err, "metadata ->")
+		}
+	}
+
+	// Parse acl
+	if o := list.Filter("acl"); len(o.Items) > 0 {
+		if err := parseACL(&result.ACL, o); err != nil {
+			return multierror.Prefix(err, "acl ->")
+		}
+	}
+
+	// Parse audit
+	if o := list.Filter("audit"); len(o.Items) > 0 {
+		if err := parseAudit(&result.Audit, o); err != nil {
+			return multierror.Prefix(err, "audit ->")
+		}
+	}
+
+	// Parse peers
+	if o := list.Filter("peer"); len(o.Items) > 0 {
+		if err := parsePeers(&result.Peers, o); err != nil {
+			return multierror.Prefix(err, "peer ->")
+		}
+	}
+
+	// Parse webhooks
+	if o := list.Filter("webhook"); len(o.Items) > 0 {
+		if err := parseWebhooks(&result.Webhooks, o); err != nil {
+			return multierror.Prefix(err, "webhook ->")
+		}
+	}
+
+	// Parse quotas
+	if o := list.Filter("quota"); len(o.Items) > 0 {
+		if err := parseQuotas(&result.Quotas, o); err != nil {
+			return multierror.Prefix(err, "quota ->")
+		}
+	}
+
+	// Parse admission policies
+	if o := list.Filter("admission"); len(o.Items) > 0 {
+		if err := parseAdmissions(&result.Admissions, o); err != nil {
+			return multierror.Prefix(err, "admission ->")
+		}
+	}
+
+	// Parse storage classes
+	if o := list.Filter("storage_class"); len(o.Items) > 0 {
+		if err := parseStorageClasses(&result.StorageClasses, o); err != nil {
+			return multierror.Prefix(err, "storage_class ->")
+		}
+	}
+
 	// Parse the nomad config
 	//if o := list.Filter("nomad"); len(o.Items) > 0 {
 	//	if err := parseNomadConfig(&result.Nomad, o); err != nil {
@@ -163,6 +238,7 @@ func parsePorts(result **Ports, list *ast.ObjectList) error {
 	// Check for invalid keys
 	valid := []string{
 		"http",
+		"admin",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
 		return err
@@ -193,6 +269,7 @@ func parseAddresses(result **Addresses, list *ast.ObjectList) error {
 	// Check for invalid keys
 	valid := []string{
 		"http",
+		"admin",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
 		return err
@@ -241,6 +318,389 @@ func parseAdvertise(result **AdvertiseAddrs, list *ast.ObjectList) error {
 	return nil
 }
 
+func parseMetadata(result **Metadata, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'metadata' block allowed")
+	}
+
+	// Get our metadata object
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"instance_id",
+		"availability_zone",
+		"local_ipv4",
+		"hostname",
+		"require_token",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var metadata Metadata
+	if err := mapstructure.WeakDecode(m, &metadata); err != nil {
+		return err
+	}
+	*result = &metadata
+	return nil
+}
+
+func parseACL(result **ACL, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'acl' block allowed")
+	}
+
+	// Get our acl object
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"enabled",
+		"management_token",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var acl ACL
+	if err := mapstructure.WeakDecode(m, &acl); err != nil {
+		return err
+	}
+	*result = &acl
+	return nil
+}
+
+func parseAudit(result **Audit, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'audit' block allowed")
+	}
+
+	// Get our audit object
+	listVal := list.Items[0].Val
+
+	// Check for invalid keys
+	valid := []string{
+		"enabled",
+		"path",
+		"max_bytes",
+		"max_files",
+	}
+	if err := checkHCLKeys(listVal, valid); err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, listVal); err != nil {
+		return err
+	}
+
+	var audit Audit
+	if err := mapstructure.WeakDecode(m, &audit); err != nil {
+		return err
+	}
+	*result = &audit
+	return nil
+}
+
+func parsePeers(result *[]*Peer, list *ast.ObjectList) error {
+	list = list.Children()
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 {
+			return fmt.Errorf("'peer' block must be keyed by its region")
+		}
+		region := item.Keys[0].Token.Value().(string)
+		if _, ok := seen[region]; ok {
+			return fmt.Errorf("peer '%s' defined more than once", region)
+		}
+		seen[region] = struct{}{}
+
+		// Check for invalid keys
+		valid := []string{
+			"datacenter",
+			"addresses",
+			"timeout",
+		}
+		if err := checkHCLKeys(item.Val, valid); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", region))
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		peer := Peer{Region: region}
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &peer,
+		})
+		if err != nil {
+			return err
+		}
+		if err := dec.Decode(m); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", region))
+		}
+
+		if len(peer.Addresses) == 0 {
+			return fmt.Errorf("'%s': at least one address is required", region)
+		}
+
+		*result = append(*result, &peer)
+	}
+
+	return nil
+}
+
+func parseWebhooks(result *[]*Webhook, list *ast.ObjectList) error {
+	list = list.Children()
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 {
+			return fmt.Errorf("'webhook' block must be keyed by its name")
+		}
+		name := item.Keys[0].Token.Value().(string)
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("webhook '%s' defined more than once", name)
+		}
+		seen[name] = struct{}{}
+
+		// Check for invalid keys
+		valid := []string{
+			"url",
+			"events",
+			"secret",
+		}
+		if err := checkHCLKeys(item.Val, valid); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", name))
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		hook := Webhook{Name: name}
+		if err := mapstructure.WeakDecode(m, &hook); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", name))
+		}
+
+		if hook.URL == "" {
+			return fmt.Errorf("'%s': url is required", name)
+		}
+
+		*result = append(*result, &hook)
+	}
+
+	return nil
+}
+
+func parseQuotas(result *[]*Quota, list *ast.ObjectList) error {
+	list = list.Children()
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 {
+			return fmt.Errorf("'quota' block must be keyed by its namespace")
+		}
+		ns := item.Keys[0].Token.Value().(string)
+		if _, ok := seen[ns]; ok {
+			return fmt.Errorf("quota '%s' defined more than once", ns)
+		}
+		seen[ns] = struct{}{}
+
+		// Check for invalid keys
+		valid := []string{
+			"max_volumes",
+			"max_capacity",
+			"max_replicas",
+		}
+		if err := checkHCLKeys(item.Val, valid); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", ns))
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		quota := Quota{Namespace: ns}
+		if err := mapstructure.WeakDecode(m, &quota); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", ns))
+		}
+
+		*result = append(*result, &quota)
+	}
+
+	return nil
+}
+
+func parseAdmissions(result *[]*Admission, list *ast.ObjectList) error {
+	list = list.Children()
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 {
+			return fmt.Errorf("'admission' block must be keyed by its name")
+		}
+		name := item.Keys[0].Token.Value().(string)
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("admission '%s' defined more than once", name)
+		}
+		seen[name] = struct{}{}
+
+		// Check for invalid keys
+		valid := []string{
+			"type",
+			"namespaces",
+			"names",
+			"labels",
+			"overwrite",
+			"forbid",
+			"require",
+			"message",
+			"url",
+			"timeout",
+			"failure_policy",
+		}
+		if err := checkHCLKeys(item.Val, valid); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", name))
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+		delete(m, "labels")
+		delete(m, "forbid")
+
+		admission := Admission{Name: name}
+		dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &admission,
+		})
+		if err != nil {
+			return err
+		}
+		if err := dec.Decode(m); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", name))
+		}
+
+		// The labels & the forbidden values are objects in HCL, hence a list
+		// that needs to be merged
+		if ot, ok := item.Val.(*ast.ObjectType); ok {
+			if o := ot.List.Filter("labels"); len(o.Items) > 0 {
+				for _, obj := range o.Elem().Items {
+					var lm map[string]interface{}
+					if err := hcl.DecodeObject(&lm, obj.Val); err != nil {
+						return err
+					}
+					if err := mapstructure.WeakDecode(lm, &admission.Labels); err != nil {
+						return multierror.Prefix(err, fmt.Sprintf("'%s': labels:", name))
+					}
+				}
+			}
+			if o := ot.List.Filter("forbid"); len(o.Items) > 0 {
+				for _, obj := range o.Elem().Items {
+					var fm map[string]interface{}
+					if err := hcl.DecodeObject(&fm, obj.Val); err != nil {
+						return err
+					}
+					if err := mapstructure.WeakDecode(fm, &admission.Forbid); err != nil {
+						return multierror.Prefix(err, fmt.Sprintf("'%s': forbid:", name))
+					}
+				}
+			}
+		}
+
+		if admission.Type != "mutating" && admission.Type != "validating" {
+			return fmt.Errorf("'%s': type must be either mutating or validating", name)
+		}
+
+		*result = append(*result, &admission)
+	}
+
+	return nil
+}
+
+func parseStorageClasses(result *[]*StorageClass, list *ast.ObjectList) error {
+	list = list.Children()
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	seen := map[string]struct{}{}
+	for _, item := range list.Items {
+		if len(item.Keys) != 1 {
+			return fmt.Errorf("'storage_class' block must be keyed by its name")
+		}
+		name := item.Keys[0].Token.Value().(string)
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("storage class '%s' defined more than once", name)
+		}
+		seen[name] = struct{}{}
+
+		// Check for invalid keys
+		valid := []string{
+			"replica_count",
+			"replica_image",
+			"replica_topology_key",
+			"controller_image",
+			"persistent_path",
+			"storage_size",
+			"orchestrator",
+			"namespace",
+		}
+		if err := checkHCLKeys(item.Val, valid); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", name))
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		class := StorageClass{Name: name}
+		if err := mapstructure.WeakDecode(m, &class); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("'%s':", name))
+		}
+
+		*result = append(*result, &class)
+	}
+
+	return nil
+}
+
 func checkHCLKeys(node ast.Node, valid []string) error {
 	var list *ast.ObjectList
 	switch n := node.(type) {
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestMayaConfig_Parse(t *testing.T) {
@@ -23,10 +24,12 @@ func TestMayaConfig_Parse(t *testing.T) {
 				BindAddr:    "192.168.0.1",
 				EnableDebug: true,
 				Ports: &Ports{
-					HTTP: 1234,
+					HTTP:  1234,
+					Admin: 1235,
 				},
 				Addresses: &Addresses{
-					HTTP: "127.0.0.1",
+					HTTP:  "127.0.0.1",
+					Admin: "127.0.0.2",
 				},
 				AdvertiseAddrs: &AdvertiseAddrs{},
 				LeaveOnInt:     true,
@@ -36,6 +39,86 @@ func TestMayaConfig_Parse(t *testing.T) {
 				HTTPAPIResponseHeaders: map[string]string{
 					"Access-Control-Allow-Origin": "*",
 				},
+				LatestAPIVersion:   "v2",
+				MaxRequestBodySize: 2097152,
+				StrictDecoding:     true,
+				Metadata: &Metadata{
+					InstanceID:       "i-0123456789",
+					AvailabilityZone: "bang-east-1a",
+					LocalIPv4:        "10.10.10.10",
+					Hostname:         "maya-1",
+					RequireToken:     true,
+				},
+				ACL: &ACL{
+					Enabled:         true,
+					ManagementToken: "s3cr3t",
+				},
+				Audit: &Audit{
+					Enabled:  true,
+					Path:     "/var/log/mayaserver",
+					MaxBytes: 1048576,
+					MaxFiles: 3,
+				},
+				Peers: []*Peer{
+					{
+						Region:     "BANG-WEST",
+						Datacenter: "dc3",
+						Addresses:  []string{"10.10.20.1:5656", "http://10.10.20.2:5656"},
+						Timeout:    5 * time.Second,
+					},
+				},
+				Webhooks: []*Webhook{
+					{
+						Name:   "cmdb",
+						URL:    "https://cmdb.example.com/hooks/maya",
+						Events: []string{"volume.created", "volume.deleted"},
+						Secret: "h00k",
+					},
+				},
+				Quotas: []*Quota{
+					{
+						Namespace:   "team-a",
+						MaxVolumes:  10,
+						MaxCapacity: "100G",
+						MaxReplicas: 3,
+					},
+				},
+				Admissions: []*Admission{
+					{
+						Name:       "default-image",
+						Type:       "mutating",
+						Namespaces: []string{"team-*"},
+						Labels: map[string]string{
+							"volumeprovisioner.mapi.openebs.io/replica-image": "openebs/jiva:0.4.0",
+						},
+					},
+					{
+						Name:       "prod-replicas",
+						Type:       "validating",
+						Namespaces: []string{"prod"},
+						Forbid: map[string][]string{
+							"volumeprovisioner.mapi.openebs.io/replica-count": {"1"},
+						},
+						Message: "prod VSMs need at least 2 replicas",
+					},
+					{
+						Name:          "cmdb",
+						Type:          "validating",
+						URL:           "https://cmdb.example.com/admit",
+						Timeout:       5 * time.Second,
+						FailurePolicy: "ignore",
+					},
+				},
+				StorageClasses: []*StorageClass{
+					{
+						Name:           "fast",
+						ReplicaCount:   3,
+						ReplicaImage:   "openebs/jiva:0.4.0",
+						PersistentPath: "/var/openebs/fast",
+						Orchestrator:   "kubernetes",
+						Namespace:      "storage",
+					},
+				},
 			},
 			false,
 		},
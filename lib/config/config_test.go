@@ -38,6 +38,12 @@ func TestMayaConfig_Merge(t *testing.T) {
 		HTTPAPIResponseHeaders: map[string]string{
 			"Access-Control-Allow-Origin": "*",
 		},
+		LatestAPIVersion: "v1",
+		Metadata: &Metadata{
+			InstanceID: "i-1",
+		},
+		ACL:   &ACL{},
+		Audit: &Audit{},
 	}
 
 	c2 := &MayaConfig{
@@ -53,16 +59,69 @@ func TestMayaConfig_Merge(t *testing.T) {
 		SyslogFacility: "local0.debug",
 		BindAddr:       "127.0.0.2",
 		Ports: &Ports{
-			HTTP: 20000,
+			HTTP:  20000,
+			Admin: 20001,
 		},
 		Addresses: &Addresses{
-			HTTP: "127.0.0.2",
+			HTTP:  "127.0.0.2",
+			Admin: "127.0.0.3",
 		},
 		AdvertiseAddrs: &AdvertiseAddrs{},
 		HTTPAPIResponseHeaders: map[string]string{
 			"Access-Control-Allow-Origin":  "*",
 			"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
 		},
+		LatestAPIVersion:   "v2",
+		MaxRequestBodySize: 2097152,
+		StrictDecoding:     true,
+		Metadata: &Metadata{
+			InstanceID:       "i-2",
+			AvailabilityZone: "region2-dc2",
+			LocalIPv4:        "10.0.0.2",
+			Hostname:         "node2",
+			RequireToken:     true,
+		},
+		ACL: &ACL{
+			Enabled:         true,
+			ManagementToken: "s3cr3t",
+		},
+		Audit: &Audit{
+			Enabled:  true,
+			Path:     "/var/log/mayaserver",
+			MaxBytes: 1048576,
+			MaxFiles: 3,
+		},
+		Peers: []*Peer{
+			{
+				Region:    "region3",
+				Addresses: []string{"10.0.0.3:5656"},
+			},
+		},
+		Webhooks: []*Webhook{
+			{
+				Name: "cmdb",
+				URL:  "http://cmdb/hooks",
+			},
+		},
+		Quotas: []*Quota{
+			{
+				Namespace:  "team-a",
+				MaxVolumes: 10,
+			},
+		},
+		Admissions: []*Admission{
+			{
+				Name:   "default-image",
+				Type:   "mutating",
+				Labels: map[string]string{"volumeprovisioner.mapi.openebs.io/replica-image": "openebs/jiva:0.4.0"},
+			},
+		},
+		StorageClasses: []*StorageClass{
+			{
+				Name:         "fast",
+				ReplicaCount: 3,
+			},
+		},
 	}
 
 	result := c1.Merge(c2)
@@ -71,6 +130,69 @@ func TestMayaConfig_Merge(t *testing.T) {
 	}
 }
 
+func TestMayaConfig_MergePeers(t *testing.T) {
+	c1 := &MayaConfig{
+		Peers: []*Peer{
+			{Region: "region2", Addresses: []string{"10.0.0.2:5656"}},
+			{Region: "region3", Addresses: []string{"10.0.0.3:5656"}},
+		},
+	}
+
+	c2 := &MayaConfig{
+		Peers: []*Peer{
+			{Region: "region3", Addresses: []string{"10.0.1.3:5656"}},
+			{Region: "region4", Addresses: []string{"10.0.0.4:5656"}},
+		},
+	}
+
+	result := c1.Merge(c2)
+	if len(result.Peers) != 3 {
+		t.Fatalf("bad: expected 3 peers, got: %d", len(result.Peers))
+	}
+
+	if p := result.Peer("region3"); p == nil || p.Addresses[0] != "10.0.1.3:5656" {
+		t.Fatalf("bad: expected region3 to be replaced: %#v", p)
+	}
+
+	if p := result.Peer("region2"); p == nil {
+		t.Fatalf("bad: expected region2 to be retained")
+	}
+
+	if p := result.Peer("region5"); p != nil {
+		t.Fatalf("bad: unexpected peer: %#v", p)
+	}
+}
+
+func TestMayaConfig_MergeAdmissions(t *testing.T) {
+	c1 := &MayaConfig{
+		Admissions: []*Admission{
+			{Name: "first", Type: "mutating"},
+			{Name: "second", Type: "validating", Message: "old"},
+		},
+	}
+
+	c2 := &MayaConfig{
+		Admissions: []*Admission{
+			{Name: "third", Type: "validating"},
+			{Name: "second", Type: "validating", Message: "new"},
+		},
+	}
+
+	// A replaced policy retains its position
+	result := c1.Merge(c2)
+	names := []string{}
+	for _, a := range result.Admissions {
+		names = append(names, a.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"first", "second", "third"}) {
+		t.Fatalf("bad: unexpected order: %v", names)
+	}
+
+	if a := result.Admission("second"); a == nil || a.Message != "new" {
+		t.Fatalf("bad: expected second to be replaced: %#v", a)
+	}
+}
+
 func TestConfig_ParseMayaConfigFile(t *testing.T) {
 	// Fails if the file doesn't exist
 	if _, err := ParseMayaConfigFile("/unicorns/leprechauns"); err == nil {
@@ -0,0 +1,155 @@
+// Package event records the notable occurrences of maya api server & its
+// volumes in a bounded in-memory store. Similar events are aggregated into
+// a single event with a count, in the manner of Kubernetes events.
+package event
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Type is the severity of an event
+type Type string
+
+const (
+	// Normal events are informational
+	Normal Type = "Normal"
+
+	// Warning events report a failure or a degradation
+	Warning Type = "Warning"
+)
+
+// ParseType returns the event type of the given value
+func ParseType(value string) (Type, error) {
+	for _, t := range []Type{Normal, Warning} {
+		if string(t) == value {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("Invalid event type '%s': valid values are %s & %s", value, Normal, Warning)
+}
+
+// DefaultMaxEvents is the number of events retained if the store is not
+// given a size
+const DefaultMaxEvents = 1000
+
+// Event is an occurrence that is recorded once & then counted every time
+// it recurs
+type Event struct {
+	Type    Type   `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+
+	// Volume is the name of the involved VSM. It is empty for the events of
+	// maya api server itself.
+	Volume string `json:"volume,omitempty"`
+
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// key identifies the recurrences of an event
+func (e *Event) key() string {
+	return fmt.Sprintf("%s/%s/%s/%s", e.Type, e.Reason, e.Volume, e.Message)
+}
+
+// Filter selects the events to list. The zero value selects every event.
+type Filter struct {
+	Volume string
+	Type   Type
+}
+
+// Matches returns true if the event passes the filter
+func (f Filter) Matches(e *Event) bool {
+	if f.Volume != "" && f.Volume != e.Volume {
+		return false
+	}
+	if f.Type != "" && f.Type != e.Type {
+		return false
+	}
+	return true
+}
+
+// Store retains the most recently seen events. The least recently seen
+// event is evicted once the store is full.
+type Store struct {
+	max int
+
+	lock   sync.Mutex
+	order  *list.List
+	events map[string]*list.Element
+}
+
+// NewStore creates a store that retains at most max events. The default is
+// used if max is not positive.
+func NewStore(max int) *Store {
+	if max <= 0 {
+		max = DefaultMaxEvents
+	}
+
+	return &Store{
+		max:    max,
+		order:  list.New(),
+		events: map[string]*list.Element{},
+	}
+}
+
+// Record records an event. A recurrence of an event that is retained
+// increments its count.
+func (s *Store) Record(t Type, reason, volume, message string) {
+	now := time.Now().UTC()
+	e := &Event{
+		Type:    t,
+		Reason:  reason,
+		Volume:  volume,
+		Message: message,
+	}
+	key := e.key()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if elem, ok := s.events[key]; ok {
+		existing := elem.Value.(*Event)
+		existing.Count++
+		existing.LastSeen = now
+		s.order.MoveToBack(elem)
+		return
+	}
+
+	e.Count = 1
+	e.FirstSeen = now
+	e.LastSeen = now
+	s.events[key] = s.order.PushBack(e)
+
+	for s.order.Len() > s.max {
+		oldest := s.order.Front()
+		s.order.Remove(oldest)
+		delete(s.events, oldest.Value.(*Event).key())
+	}
+}
+
+// Recordf records an event with a formatted message
+func (s *Store) Recordf(t Type, reason, volume, format string, args ...interface{}) {
+	s.Record(t, reason, volume, fmt.Sprintf(format, args...))
+}
+
+// List returns copies of the events that match the filter, least recently
+// seen first
+func (s *Store) List(f Filter) []*Event {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	events := []*Event{}
+	for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+		e := elem.Value.(*Event)
+		if f.Matches(e) {
+			copied := *e
+			events = append(events, &copied)
+		}
+	}
+	return events
+}
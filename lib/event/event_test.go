@@ -0,0 +1,72 @@
+package event
+
+import (
+	"testing"
+)
+
+func TestStoreAggregates(t *testing.T) {
+	s := NewStore(0)
+
+	s.Record(Warning, "ProvisioningFailed", "vol1", "no nodes available")
+	s.Record(Normal, "Provisioned", "vol2", "VSM is provisioned")
+	s.Record(Warning, "ProvisioningFailed", "vol1", "no nodes available")
+
+	events := s.List(Filter{})
+	if len(events) != 2 {
+		t.Fatalf("bad: expected 2 events, got: %+v", events)
+	}
+
+	// The recurrence is the most recently seen
+	e := events[1]
+	if e.Volume != "vol1" || e.Count != 2 || e.LastSeen.Before(e.FirstSeen) {
+		t.Fatalf("bad: %+v", e)
+	}
+
+	// A different message is a different event
+	s.Recordf(Warning, "ProvisioningFailed", "vol1", "timed out after %s", "30s")
+
+	cases := []struct {
+		f        Filter
+		expected int
+	}{
+		{Filter{}, 3},
+		{Filter{Volume: "vol1"}, 2},
+		{Filter{Type: Normal}, 1},
+		{Filter{Volume: "vol2", Type: Warning}, 0},
+	}
+	for _, c := range cases {
+		if actual := s.List(c.f); len(actual) != c.expected {
+			t.Fatalf("bad: %+v: expected %d events, got: %d", c.f, c.expected, len(actual))
+		}
+	}
+}
+
+func TestStoreEvicts(t *testing.T) {
+	s := NewStore(2)
+
+	s.Record(Normal, "Provisioned", "vol1", "")
+	s.Record(Normal, "Provisioned", "vol2", "")
+	s.Record(Normal, "Provisioned", "vol1", "")
+	s.Record(Normal, "Provisioned", "vol3", "")
+
+	// vol2 is the least recently seen
+	events := s.List(Filter{})
+	if len(events) != 2 || events[0].Volume != "vol1" || events[1].Volume != "vol3" {
+		t.Fatalf("bad: %+v", events)
+	}
+
+	// The listed events are copies
+	events[0].Count = 10
+	if e := s.List(Filter{Volume: "vol1"})[0]; e.Count != 2 {
+		t.Fatalf("bad: %+v", e)
+	}
+}
+
+func TestParseType(t *testing.T) {
+	if typ, err := ParseType("Warning"); err != nil || typ != Warning {
+		t.Fatalf("bad: %s, err: %v", typ, err)
+	}
+	if _, err := ParseType("Error"); err == nil {
+		t.Fatalf("expected an invalid type error")
+	}
+}
@@ -7,9 +7,11 @@ bind_addr = "192.168.0.1"
 enable_debug = true
 ports {
 	http = 1234
+	admin = 1235
 }
 addresses {
 	http = "127.0.0.1"
+	admin = "127.0.0.2"
 }
 advertise {
 }
@@ -20,3 +22,66 @@ syslog_facility = "LOCAL1"
 http_api_response_headers {
 	Access-Control-Allow-Origin = "*"
 }
+latest_api_version = "v2"
+max_request_body_size = 2097152
+strict_decoding = true
+metadata {
+	instance_id = "i-0123456789"
+	availability_zone = "bang-east-1a"
+	local_ipv4 = "10.10.10.10"
+	hostname = "maya-1"
+	require_token = true
+}
+acl {
+	enabled = true
+	management_token = "s3cr3t"
+}
+audit {
+	enabled = true
+	path = "/var/log/mayaserver"
+	max_bytes = 1048576
+	max_files = 3
+}
+peer "BANG-WEST" {
+	datacenter = "dc3"
+	addresses = ["10.10.20.1:5656", "http://10.10.20.2:5656"]
+	timeout = "5s"
+}
+webhook "cmdb" {
+	url = "https://cmdb.example.com/hooks/maya"
+	events = ["volume.created", "volume.deleted"]
+	secret = "h00k"
+}
+quota "team-a" {
+	max_volumes = 10
+	max_capacity = "100G"
+	max_replicas = 3
+}
+admission "default-image" {
+	type = "mutating"
+	namespaces = ["team-*"]
+	labels {
+		"volumeprovisioner.mapi.openebs.io/replica-image" = "openebs/jiva:0.4.0"
+	}
+}
+admission "prod-replicas" {
+	type = "validating"
+	namespaces = ["prod"]
+	forbid {
+		"volumeprovisioner.mapi.openebs.io/replica-count" = ["1"]
+	}
+	message = "prod VSMs need at least 2 replicas"
+}
+admission "cmdb" {
+	type = "validating"
+	url = "https://cmdb.example.com/admit"
+	timeout = "5s"
+	failure_policy = "ignore"
+}
+storage_class "fast" {
+	replica_count = 3
+	replica_image = "openebs/jiva:0.4.0"
+	persistent_path = "/var/openebs/fast"
+	orchestrator = "kubernetes"
+	namespace = "storage"
+}
@@ -0,0 +1,287 @@
+// Package quota limits the VSMs that may be provisioned in a namespace. A
+// quota is either configured or set through the API. A quota that is set
+// through the API overrides the configured quota of its namespace.
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	mayav1 "github.com/openebs/maya/types/v1"
+)
+
+const (
+	// SourceConfig is the source of a configured quota
+	SourceConfig = "config"
+
+	// SourceAPI is the source of a quota that is set through the API
+	SourceAPI = "api"
+)
+
+// Quota limits the VSMs of a namespace. A limit that is not set is not
+// enforced.
+type Quota struct {
+	Namespace string `json:"namespace"`
+
+	// MaxVolumes is the maximum number of VSMs
+	MaxVolumes int `json:"maxVolumes,omitempty"`
+
+	// MaxCapacity is the maximum total capacity of the VSMs e.g. 100G
+	MaxCapacity string `json:"maxCapacity,omitempty"`
+
+	// MaxReplicas is the maximum number of replicas of a single VSM
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+
+	// Source is either config or api
+	Source string `json:"source,omitempty"`
+}
+
+// Validate returns an error if a limit of the quota is invalid
+func (q *Quota) Validate() error {
+	if q.Namespace == "" {
+		return fmt.Errorf("quota namespace is missing")
+	}
+	if q.MaxVolumes < 0 || q.MaxReplicas < 0 {
+		return fmt.Errorf("quota of namespace '%s' can not have negative limits", q.Namespace)
+	}
+	if q.MaxCapacity != "" {
+		if _, err := mayav1.ParseQuantity(q.MaxCapacity); err != nil {
+			return fmt.Errorf("quota of namespace '%s' has an invalid max capacity '%s': must be a size e.g. 100G", q.Namespace, q.MaxCapacity)
+		}
+	}
+	return nil
+}
+
+// maxCapacityBytes returns the max capacity in bytes. Zero means the
+// capacity is not limited.
+func (q *Quota) maxCapacityBytes() int64 {
+	if q.MaxCapacity == "" {
+		return 0
+	}
+	c, err := mayav1.ParseQuantity(q.MaxCapacity)
+	if err != nil {
+		return 0
+	}
+	return c.Value()
+}
+
+// Usage is the provisioned VSMs of a namespace
+type Usage struct {
+	Volumes       int   `json:"volumes"`
+	CapacityBytes int64 `json:"capacityBytes"`
+}
+
+// Request is a VSM that is to be provisioned
+type Request struct {
+	CapacityBytes int64
+	Replicas      int
+}
+
+// CheckVolume returns an error if the VSM by itself exceeds the quota
+// irrespective of the usage
+func (q *Quota) CheckVolume(r Request) error {
+	if q.MaxReplicas > 0 && r.Replicas > q.MaxReplicas {
+		return fmt.Errorf("Quota of namespace '%s' allows at most %d replicas per VSM, requested %d", q.Namespace, q.MaxReplicas, r.Replicas)
+	}
+	if max := q.maxCapacityBytes(); max > 0 && r.CapacityBytes > max {
+		return fmt.Errorf("Quota of namespace '%s' allows at most %s of capacity, requested %d bytes", q.Namespace, q.MaxCapacity, r.CapacityBytes)
+	}
+	return nil
+}
+
+// Check returns an error if provisioning the VSM would exceed the quota
+// given the current usage of the namespace
+func (q *Quota) Check(u Usage, r Request) error {
+	if err := q.CheckVolume(r); err != nil {
+		return err
+	}
+	if q.MaxVolumes > 0 && u.Volumes+1 > q.MaxVolumes {
+		return fmt.Errorf("Quota of namespace '%s' allows at most %d VSMs, %d are provisioned", q.Namespace, q.MaxVolumes, u.Volumes)
+	}
+	if max := q.maxCapacityBytes(); max > 0 && u.CapacityBytes+r.CapacityBytes > max {
+		return fmt.Errorf("Quota of namespace '%s' allows at most %s of capacity, %d bytes are provisioned & %d bytes are requested", q.Namespace, q.MaxCapacity, u.CapacityBytes, r.CapacityBytes)
+	}
+	return nil
+}
+
+// Store holds the configured quotas & the quotas that are set through the
+// API. The latter are persisted to a file if a path is given.
+type Store struct {
+	path string
+
+	lock       sync.Mutex
+	configured map[string]*Quota
+	overrides  map[string]*Quota
+}
+
+// NewStore creates a store of the configured quotas. The quotas that were
+// set through the API are loaded from the path if it exists.
+func NewStore(path string, configured []*Quota) (*Store, error) {
+	s := &Store{
+		path:       path,
+		configured: map[string]*Quota{},
+		overrides:  map[string]*Quota{},
+	}
+
+	for _, q := range configured {
+		if err := q.Validate(); err != nil {
+			return nil, err
+		}
+		copied := *q
+		copied.Source = SourceConfig
+		s.configured[q.Namespace] = &copied
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads the persisted quotas
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var quotas []*Quota
+	if err := json.Unmarshal(b, &quotas); err != nil {
+		return fmt.Errorf("failed to load quotas from %s: %v", s.path, err)
+	}
+	for _, q := range quotas {
+		q.Source = SourceAPI
+		s.overrides[q.Namespace] = q
+	}
+	return nil
+}
+
+// persist writes the quotas that were set through the API. The file is
+// replaced atomically.
+func (s *Store) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	quotas := make([]*Quota, 0, len(s.overrides))
+	for _, q := range s.overrides {
+		quotas = append(quotas, q)
+	}
+	sortQuotas(quotas)
+
+	b, err := json.Marshal(quotas)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Get returns a copy of the quota of the namespace. Nil is returned if the
+// namespace has no quota.
+func (s *Store) Get(namespace string) *Quota {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	q := s.overrides[namespace]
+	if q == nil {
+		q = s.configured[namespace]
+	}
+	if q == nil {
+		return nil
+	}
+	copied := *q
+	return &copied
+}
+
+// List returns copies of the quotas of every namespace sorted by namespace
+func (s *Store) List() []*Quota {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	quotas := []*Quota{}
+	for ns, q := range s.configured {
+		if _, ok := s.overrides[ns]; !ok {
+			copied := *q
+			quotas = append(quotas, &copied)
+		}
+	}
+	for _, q := range s.overrides {
+		copied := *q
+		quotas = append(quotas, &copied)
+	}
+	sortQuotas(quotas)
+	return quotas
+}
+
+// Set sets the quota of a namespace. It overrides the configured quota of
+// the namespace if any.
+func (s *Store) Set(q *Quota) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+
+	copied := *q
+	copied.Source = SourceAPI
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	prev, existed := s.overrides[q.Namespace]
+	s.overrides[q.Namespace] = &copied
+	if err := s.persist(); err != nil {
+		if existed {
+			s.overrides[q.Namespace] = prev
+		} else {
+			delete(s.overrides, q.Namespace)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the quota of a namespace that was set through the API. The
+// configured quota of the namespace, if any, applies again. False is
+// returned if no quota was set through the API.
+func (s *Store) Delete(namespace string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	prev, ok := s.overrides[namespace]
+	if !ok {
+		return false, nil
+	}
+
+	delete(s.overrides, namespace)
+	if err := s.persist(); err != nil {
+		s.overrides[namespace] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// sortQuotas sorts the quotas by namespace
+func sortQuotas(quotas []*Quota) {
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Namespace < quotas[j].Namespace
+	})
+}
@@ -0,0 +1,104 @@
+package quota
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuotaCheck(t *testing.T) {
+	q := &Quota{Namespace: "team-a", MaxVolumes: 2, MaxCapacity: "10G", MaxReplicas: 3}
+
+	cases := []struct {
+		u   Usage
+		r   Request
+		err bool
+	}{
+		{Usage{}, Request{CapacityBytes: 1e9, Replicas: 2}, false},
+		{Usage{}, Request{CapacityBytes: 1e9, Replicas: 4}, true},
+		{Usage{}, Request{CapacityBytes: 11e9, Replicas: 1}, true},
+		{Usage{Volumes: 1, CapacityBytes: 9e9}, Request{CapacityBytes: 1e9, Replicas: 1}, false},
+		{Usage{Volumes: 1, CapacityBytes: 9e9}, Request{CapacityBytes: 2e9, Replicas: 1}, true},
+		{Usage{Volumes: 2, CapacityBytes: 2e9}, Request{CapacityBytes: 1e9, Replicas: 1}, true},
+	}
+
+	for _, c := range cases {
+		if err := q.Check(c.u, c.r); (err != nil) != c.err {
+			t.Fatalf("bad: %+v, %+v: expected error: %v, got: %v", c.u, c.r, c.err, err)
+		}
+	}
+
+	// A limit that is not set is not enforced
+	q = &Quota{Namespace: "team-b"}
+	if err := q.Check(Usage{Volumes: 100, CapacityBytes: 1e12}, Request{CapacityBytes: 1e12, Replicas: 10}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestQuotaValidate(t *testing.T) {
+	for _, q := range []*Quota{
+		{},
+		{Namespace: "team-a", MaxVolumes: -1},
+		{Namespace: "team-a", MaxCapacity: "lots"},
+	} {
+		if err := q.Validate(); err == nil {
+			t.Fatalf("bad: %+v: expected an error", q)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quota")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "quotas.json")
+	configured := []*Quota{
+		{Namespace: "team-a", MaxVolumes: 2},
+		{Namespace: "team-b", MaxVolumes: 5},
+	}
+
+	s, err := NewStore(path, configured)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The API overrides the configured quota
+	if err := s.Set(&Quota{Namespace: "team-a", MaxVolumes: 4}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := s.Set(&Quota{Namespace: "team-c", MaxReplicas: 1}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if q := s.Get("team-a"); q.MaxVolumes != 4 || q.Source != SourceAPI {
+		t.Fatalf("bad: %+v", q)
+	}
+
+	// The quotas set through the API are reloaded
+	s, err = NewStore(path, configured)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	quotas := s.List()
+	if len(quotas) != 3 || quotas[0].MaxVolumes != 4 || quotas[1].Source != SourceConfig || quotas[2].Namespace != "team-c" {
+		t.Fatalf("bad: %+v", quotas)
+	}
+
+	// The configured quota applies once the override is deleted
+	if ok, err := s.Delete("team-a"); !ok || err != nil {
+		t.Fatalf("bad: %v, err: %v", ok, err)
+	}
+	if q := s.Get("team-a"); q.MaxVolumes != 2 || q.Source != SourceConfig {
+		t.Fatalf("bad: %+v", q)
+	}
+	if ok, _ := s.Delete("team-b"); ok {
+		t.Fatalf("bad: a configured quota can not be deleted")
+	}
+	if q := s.Get("team-d"); q != nil {
+		t.Fatalf("bad: %+v", q)
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+const (
+	// MayaTokenHeader is the request header that carries the management
+	// token
+	MayaTokenHeader = "X-Maya-Token"
+
+	// ErrPermissionDenied is used if the management token is missing or
+	// does not match
+	ErrPermissionDenied = "Permission denied"
+)
+
+// checkACL verifies the management token of the request. Every request is
+// allowed if the acl is not enabled.
+func (s *HTTPServer) checkACL(req *http.Request) error {
+	acl := s.maya.config.ACL
+	if acl == nil || !acl.Enabled {
+		return nil
+	}
+
+	token := req.Header.Get(MayaTokenHeader)
+	if token == "" || acl.ManagementToken == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(acl.ManagementToken)) != 1 {
+		return CodedError(403, ErrPermissionDenied)
+	}
+
+	return nil
+}
+
+// aclHandler guards a plain http handler with the management token
+func (s *HTTPServer) aclHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if err := s.checkACL(req); err != nil {
+			s.logger.Printf("[ERR] http: Request %v %v, error: %v", req.Method, req.URL, err)
+			resp.WriteHeader(403)
+			resp.Write([]byte(err.Error()))
+			return
+		}
+		handler.ServeHTTP(resp, req)
+	})
+}
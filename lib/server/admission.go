@@ -0,0 +1,50 @@
+package server
+
+import (
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/mayaserver/lib/admission"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/event"
+)
+
+// admitClaim applies the storage class of the claim & then runs the
+// mutating policies, the validation & the validating policies on the claim
+// in that order. A denied claim is a 403 coded error while an invalid claim
+// is a 422 coded error.
+//
+// NOTE:
+//    The validation runs after the storage class & the mutating policies,
+// so the labels they set are validated as well.
+func (s *HTTPServer) admitClaim(pvc *v1.PersistentVolumeClaim) error {
+	chain := s.maya.admission
+
+	if err := s.applyStorageClass(pvc); err != nil {
+		return err
+	}
+
+	if err := chain.Mutate(pvc); err != nil {
+		return s.admissionError(pvc, err)
+	}
+
+	// Every violation is reported before the orchestrator is invoked
+	if err := mapiv1.ValidateClaim(pvc); err != nil {
+		return CodedError(422, err.Error())
+	}
+
+	if err := chain.Validate(pvc); err != nil {
+		return s.admissionError(pvc, err)
+	}
+	return nil
+}
+
+// admissionError records the failed admission of the claim as an event &
+// returns the coded error
+func (s *HTTPServer) admissionError(pvc *v1.PersistentVolumeClaim, err error) error {
+	if admission.IsDenied(err) {
+		s.maya.events.Record(event.Warning, "AdmissionDenied", pvc.Name, err.Error())
+		return CodedError(403, err.Error())
+	}
+
+	s.maya.events.Record(event.Warning, "AdmissionFailed", pvc.Name, err.Error())
+	return CodedError(500, err.Error())
+}
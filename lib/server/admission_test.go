@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/mayaserver/lib/admission"
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/event"
+)
+
+func TestAdmitClaim(t *testing.T) {
+	// The external webhook denies the claims named frozen-*
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := &admission.Review{}
+		json.NewDecoder(r.Body).Decode(review)
+		json.NewEncoder(w).Encode(&admission.Response{
+			Allowed: !strings.HasPrefix(review.Claim.Name, "frozen-"),
+			Reason:  "VSM is frozen",
+		})
+	}))
+	defer hook.Close()
+
+	replicas := string(v1.PVPReplicaCountLbl)
+	image := string(v1.PVPReplicaImageLbl)
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Admissions = []*config.Admission{
+			{
+				Name:       "default-image",
+				Type:       "mutating",
+				Namespaces: []string{"team-*"},
+				Labels:     map[string]string{image: "openebs/jiva:0.4.0"},
+			},
+			{
+				Name:   "too-many-replicas",
+				Type:   "mutating",
+				Names:  []string{"huge-*"},
+				Labels: map[string]string{replicas: "20"},
+			},
+			{
+				Name:       "prod-replicas",
+				Type:       "validating",
+				Namespaces: []string{"prod"},
+				Forbid:     map[string][]string{replicas: {"1"}},
+				Message:    "prod VSMs need at least 2 replicas",
+			},
+			{
+				Name: "freeze",
+				Type: "validating",
+				URL:  hook.URL,
+			},
+		}
+	})
+	defer s.Cleanup()
+
+	claim := func(name, ns string, labels map[string]string) *v1.PersistentVolumeClaim {
+		pvc := &v1.PersistentVolumeClaim{}
+		pvc.Name = name
+		pvc.Labels = map[string]string{string(v1.OrchNSLbl): ns}
+		for k, v := range labels {
+			pvc.Labels[k] = v
+		}
+		return pvc
+	}
+
+	pvc := claim("vol1", "team-a", nil)
+	if err := s.Server.admitClaim(pvc); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if pvc.Labels[image] != "openebs/jiva:0.4.0" {
+		t.Fatalf("ERR: expected the default image, got: %v", pvc.Labels)
+	}
+
+	cases := []struct {
+		pvc  *v1.PersistentVolumeClaim
+		code int
+	}{
+		{claim("vol1", "prod", map[string]string{replicas: "1"}), 403},
+		{claim("vol1", "prod", map[string]string{replicas: "2"}), 0},
+		{claim("frozen-1", "dev", nil), 403},
+		// The labels set by the mutating policies are validated
+		{claim("huge-1", "dev", nil), 422},
+	}
+
+	for _, c := range cases {
+		err := s.Server.admitClaim(c.pvc)
+		if c.code == 0 {
+			if err != nil {
+				t.Fatalf("ERR: %s: %v", c.pvc.Name, err)
+			}
+			continue
+		}
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != c.code {
+			t.Fatalf("ERR: %s: expected a %d coded error, got: %v", c.pvc.Name, c.code, err)
+		}
+	}
+
+	// The denial is served with its reason & recorded as an event
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(`{"metadata": {"name": "vol2", "labels": {
+		"orchprovider.mapi.openebs.io/ns": "prod",
+		"volumeprovisioner.mapi.openebs.io/replica-count": "1"}}}`))
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.VSMSpecificRequest)(resp, req)
+
+	if resp.Code != 403 || !strings.Contains(resp.Body.String(), "prod VSMs need at least 2 replicas") {
+		t.Fatalf("ERR: expected a 403 with the reason, got: %v %s", resp.Code, resp.Body.String())
+	}
+	if events := s.Maya.events.List(event.Filter{Volume: "vol2"}); len(events) != 1 || events[0].Reason != "AdmissionDenied" {
+		t.Fatalf("ERR: expected an AdmissionDenied event, got: %+v", events)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+// AgentSpecificRequest is a http handler implementation. It deals with HTTP
+// requests w.r.t this maya api server.
+func (s *HTTPServer) AgentSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch strings.TrimPrefix(req.URL.Path, "/latest/agent") {
+	case "/self":
+		return s.agentSelf(resp, req)
+	case "/monitor":
+		return s.agentMonitor(resp, req)
+	case "/log-level":
+		return s.agentLogLevel(resp, req)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+// agentSelf is the http handler that describes this maya api server
+func (s *HTTPServer) agentSelf(resp http.ResponseWriter, req *http.Request) (*mapiv1.AgentSelf, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	conf := s.maya.config
+	uptime := time.Since(s.maya.startTime)
+
+	self := &mapiv1.AgentSelf{
+		Version:           conf.Version,
+		Revision:          conf.Revision,
+		VersionPrerelease: conf.VersionPrerelease,
+		NodeName:          conf.NodeName,
+		Region:            conf.Region,
+		Datacenter:        conf.Datacenter,
+		StartTime:         s.maya.startTime.UTC(),
+		Uptime:            (uptime / time.Second * time.Second).String(),
+		UptimeSeconds:     int64(uptime.Seconds()),
+	}
+	if conf.AdvertiseAddrs != nil {
+		self.AdvertiseAddr = conf.AdvertiseAddrs.HTTP
+	}
+	self.LogLevel, self.LogLevelRevertAt = s.maya.LogLevel()
+
+	return self, nil
+}
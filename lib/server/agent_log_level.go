@@ -0,0 +1,163 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/logutils"
+	"github.com/openebs/mayaserver/lib/loghelper"
+)
+
+// LogLevelRequest changes the log level of this maya api server. The level
+// is reverted after the duration if one is set e.g. 10m.
+type LogLevelRequest struct {
+	Level    string `json:"level"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// LogLevelResponse is the log level after the change
+type LogLevelResponse struct {
+	Level         string     `json:"level"`
+	PreviousLevel string     `json:"previousLevel"`
+	RevertAt      *time.Time `json:"revertAt,omitempty"`
+}
+
+// LogLevel returns the current log level along with the time at which it
+// gets reverted, if any
+func (ms *MayaApiServer) LogLevel() (string, *time.Time) {
+	ms.logLevelLock.Lock()
+	defer ms.logLevelLock.Unlock()
+
+	return ms.logLevel(), ms.revertAt
+}
+
+// logLevel must be invoked with the log level lock held
+func (ms *MayaApiServer) logLevel() string {
+	if ms.logFilter != nil {
+		return string(ms.logFilter.MinLevel)
+	}
+	return strings.ToUpper(ms.config.LogLevel)
+}
+
+// SetLogLevel sets the log level & reverts it after the given duration if
+// it is positive. A pending revert is replaced; the level is then reverted
+// to the one that was set before any of the temporary changes.
+func (ms *MayaApiServer) SetLogLevel(level string, d time.Duration) (*LogLevelResponse, error) {
+	ms.logLevelLock.Lock()
+	defer ms.logLevelLock.Unlock()
+
+	if ms.logFilter == nil {
+		return nil, CodedError(503, "Log level can not be changed")
+	}
+
+	minLevel := logutils.LogLevel(strings.ToUpper(level))
+	if !loghelper.ValidateLevelFilter(minLevel, ms.logFilter) {
+		return nil, CodedError(400, fmt.Sprintf("Invalid log level: %s. Valid log levels are: %v", level, ms.logFilter.Levels))
+	}
+
+	revertTo := ms.logLevel()
+	if ms.revertTimer != nil {
+		revertTo = ms.revertLevel
+	}
+	ms.cancelLogLevelRevert()
+
+	resp := &LogLevelResponse{
+		Level:         string(minLevel),
+		PreviousLevel: ms.logLevel(),
+	}
+
+	ms.logFilter.SetMinLevel(minLevel)
+	ms.logger.Printf("[INFO] maya api server: log level set to %s", minLevel)
+
+	if d > 0 {
+		gen := ms.logLevelGen
+		revertAt := time.Now().Add(d)
+		ms.revertLevel = revertTo
+		ms.revertAt = &revertAt
+		ms.revertTimer = time.AfterFunc(d, func() {
+			ms.logLevelLock.Lock()
+			defer ms.logLevelLock.Unlock()
+
+			// The level was changed again after this timer fired but
+			// before it got the lock
+			if ms.logLevelGen != gen {
+				return
+			}
+
+			ms.logFilter.SetMinLevel(logutils.LogLevel(revertTo))
+			ms.revertTimer = nil
+			ms.revertAt = nil
+			ms.logger.Printf("[INFO] maya api server: log level reverted to %s", revertTo)
+		})
+		resp.RevertAt = &revertAt
+	}
+
+	return resp, nil
+}
+
+// ReloadLogLevel sets the log level of a reloaded config. A pending revert
+// of a temporary change is cancelled as the reloaded level takes precedence.
+func (ms *MayaApiServer) ReloadLogLevel(level string) error {
+	ms.logLevelLock.Lock()
+	defer ms.logLevelLock.Unlock()
+
+	if ms.logFilter == nil {
+		return CodedError(503, "Log level can not be changed")
+	}
+
+	minLevel := logutils.LogLevel(strings.ToUpper(level))
+	if !loghelper.ValidateLevelFilter(minLevel, ms.logFilter) {
+		return CodedError(400, fmt.Sprintf("Invalid log level: %s. Valid log levels are: %v", level, ms.logFilter.Levels))
+	}
+
+	ms.cancelLogLevelRevert()
+	ms.logFilter.SetMinLevel(minLevel)
+
+	return nil
+}
+
+// cancelLogLevelRevert stops the pending revert of the log level, if any. It
+// must be invoked with the log level lock held.
+func (ms *MayaApiServer) cancelLogLevelRevert() {
+	if ms.revertTimer != nil {
+		ms.revertTimer.Stop()
+	}
+	ms.revertTimer = nil
+	ms.revertAt = nil
+	ms.logLevelGen++
+}
+
+// agentLogLevel is the http handler that changes the log level
+//
+// NOTE:
+//    PUT /latest/agent/log-level with {"level": "DEBUG", "duration": "10m"}
+func (s *HTTPServer) agentLogLevel(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if err := s.checkACL(req); err != nil {
+		return nil, err
+	}
+
+	args := LogLevelRequest{}
+	if err := s.decodeBody(req, &args); err != nil {
+		return nil, err
+	}
+
+	if args.Level == "" {
+		return nil, CodedError(400, "Log level is missing")
+	}
+
+	var d time.Duration
+	if args.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(args.Duration); err != nil || d < 0 {
+			return nil, CodedError(400, fmt.Sprintf("Invalid duration: %s", args.Duration))
+		}
+	}
+
+	return s.maya.SetLogLevel(args.Level, d)
+}
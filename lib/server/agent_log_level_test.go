@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/loghelper"
+)
+
+func TestAgentLogLevel(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "s3cr3t"}
+	})
+	defer s.Cleanup()
+
+	filter := loghelper.LevelFilter()
+	s.Maya.SetLogFilter(filter)
+
+	body := bytes.NewBufferString(`{"level": "debug", "duration": "100ms"}`)
+	req, _ := http.NewRequest("PUT", "/latest/agent/log-level", body)
+
+	// The management token is required
+	if _, err := s.Server.AgentSpecificRequest(httptest.NewRecorder(), req); err == nil || err.Error() != ErrPermissionDenied {
+		t.Fatalf("ERR: expected: %v, got: %v", ErrPermissionDenied, err)
+	}
+
+	body = bytes.NewBufferString(`{"level": "debug", "duration": "100ms"}`)
+	req, _ = http.NewRequest("PUT", "/latest/agent/log-level", body)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+
+	out, err := s.Server.AgentSpecificRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	ll := out.(*LogLevelResponse)
+	if ll.Level != "DEBUG" || ll.PreviousLevel != "INFO" || ll.RevertAt == nil {
+		t.Fatalf("ERR: unexpected response: %+v", ll)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/agent/self", nil)
+	self, _ := s.Server.agentSelf(httptest.NewRecorder(), req)
+	if self.LogLevel != "DEBUG" || self.LogLevelRevertAt == nil {
+		t.Fatalf("ERR: expected DEBUG with a revert time, got: %s %v", self.LogLevel, self.LogLevelRevertAt)
+	}
+
+	// The level is reverted after the duration
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		level, revertAt := s.Maya.LogLevel()
+		if level == "INFO" && revertAt == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ERR: log level was not reverted: %s", level)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestInvalidAgentLogLevel(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	s.Maya.SetLogFilter(loghelper.LevelFilter())
+
+	cases := map[string]int{
+		`{"level": "oddy"}`:                    400,
+		`{"duration": "10m"}`:                  400,
+		`{"level": "DEBUG", "duration": "10"}`: 400,
+	}
+
+	for body, code := range cases {
+		req, _ := http.NewRequest("PUT", "/latest/agent/log-level", bytes.NewBufferString(body))
+
+		_, err := s.Server.AgentSpecificRequest(httptest.NewRecorder(), req)
+		if err == nil || err.(HTTPCodedError).Code() != code {
+			t.Fatalf("ERR: %s: expected: %d, got: %v", body, code, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/latest/agent/log-level", nil)
+	if _, err := s.Server.AgentSpecificRequest(httptest.NewRecorder(), req); err == nil || err.Error() != ErrInvalidMethod {
+		t.Fatalf("ERR: expected: %v, got: %v", ErrInvalidMethod, err)
+	}
+}
+
+func TestReloadLogLevel(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	s.Maya.SetLogFilter(loghelper.LevelFilter())
+
+	if _, err := s.Maya.SetLogLevel("DEBUG", 50*time.Millisecond); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	// The reloaded level cancels the pending revert
+	if err := s.Maya.ReloadLogLevel("warn"); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if level, revertAt := s.Maya.LogLevel(); level != "WARN" || revertAt != nil {
+		t.Fatalf("ERR: expected WARN without a revert time, got: %s %v", level, revertAt)
+	}
+
+	if err := s.Maya.ReloadLogLevel("oddy"); err == nil {
+		t.Fatalf("ERR: expected an invalid log level error")
+	}
+}
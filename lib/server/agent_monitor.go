@@ -0,0 +1,96 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/logutils"
+	"github.com/openebs/mayaserver/lib/loghelper"
+)
+
+// monitorBufSize is the count of log lines that are buffered per monitor.
+// Lines are dropped if the client can not keep up.
+const monitorBufSize = 512
+
+// logStreamer is a LogHandler that filters the logs as per the level of a
+// single monitor & hands them over to the monitor
+type logStreamer struct {
+	filter *logutils.LevelFilter
+	logCh  chan string
+}
+
+// HandleLog must not block since it is invoked with the registrar's lock
+// held
+func (ls *logStreamer) HandleLog(line string) {
+	if !ls.filter.Check([]byte(line)) {
+		return
+	}
+
+	select {
+	case ls.logCh <- line:
+	default:
+	}
+}
+
+// agentMonitor is the http handler that streams the logs of this maya api
+// server. The buffered logs are streamed first followed by the new ones till
+// the client disconnects.
+//
+// NOTE:
+//    GET /latest/agent/monitor?log_level=DEBUG
+func (s *HTTPServer) agentMonitor(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if err := s.checkACL(req); err != nil {
+		return nil, err
+	}
+
+	logLevel := strings.ToUpper(req.URL.Query().Get("log_level"))
+	if logLevel == "" {
+		logLevel = "INFO"
+	}
+
+	filter := loghelper.LevelFilter()
+	filter.MinLevel = logutils.LogLevel(logLevel)
+	if !loghelper.ValidateLevelFilter(filter.MinLevel, filter) {
+		return nil, CodedError(400, fmt.Sprintf("Invalid log level: %s. Valid log levels are: %v", logLevel, filter.Levels))
+	}
+
+	registrar := s.maya.logRegistrar
+	if registrar == nil {
+		return nil, CodedError(503, "Log streaming is not available")
+	}
+
+	flusher, ok := resp.(http.Flusher)
+	if !ok {
+		return nil, CodedError(500, "Streaming is not supported")
+	}
+
+	streamer := &logStreamer{
+		filter: filter,
+		logCh:  make(chan string, monitorBufSize),
+	}
+	registrar.RegisterHandler(streamer)
+	defer registrar.DeregisterHandler(streamer)
+
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	resp.WriteHeader(200)
+	flusher.Flush()
+
+	for {
+		select {
+		case line := <-streamer.logCh:
+			if _, err := fmt.Fprintln(resp, line); err != nil {
+				return nil, nil
+			}
+			flusher.Flush()
+		case <-req.Context().Done():
+			return nil, nil
+		case <-s.maya.shutdownCh:
+			return nil, nil
+		}
+	}
+}
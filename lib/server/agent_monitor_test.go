@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openebs/mayaserver/lib/loghelper"
+)
+
+func TestAgentMonitor(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	registrar := loghelper.NewLogRegistrar(16)
+	s.Maya.SetLogRegistrar(registrar)
+
+	registrar.Write([]byte("[DEBUG] buffered debug\n"))
+	registrar.Write([]byte("[INFO] buffered info\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", "/latest/agent/monitor?log_level=info", nil)
+	req = req.WithContext(ctx)
+	resp := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Server.AgentSpecificRequest(resp, req)
+	}()
+
+	// A new line is streamed as well
+	time.Sleep(50 * time.Millisecond)
+	registrar.Write([]byte("[ERR] live error\n"))
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ERR: monitor did not return on disconnect")
+	}
+
+	body := resp.Body.String()
+	if strings.Contains(body, "buffered debug") {
+		t.Fatalf("ERR: debug line must be filtered: %q", body)
+	}
+
+	if !strings.Contains(body, "buffered info") || !strings.Contains(body, "live error") {
+		t.Fatalf("ERR: expected the buffered & live lines: %q", body)
+	}
+}
+
+func TestInvalidAgentMonitor(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	// Not available without a log registrar
+	req, _ := http.NewRequest("GET", "/latest/agent/monitor", nil)
+	_, err := s.Server.AgentSpecificRequest(httptest.NewRecorder(), req)
+	if err == nil || err.(HTTPCodedError).Code() != 503 {
+		t.Fatalf("ERR: expected: 503, got: %v", err)
+	}
+
+	s.Maya.SetLogRegistrar(loghelper.NewLogRegistrar(16))
+
+	req, _ = http.NewRequest("GET", "/latest/agent/monitor?log_level=oddy", nil)
+	_, err = s.Server.AgentSpecificRequest(httptest.NewRecorder(), req)
+	if err == nil || err.(HTTPCodedError).Code() != 400 {
+		t.Fatalf("ERR: expected: 400, got: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// APIVersionHeader is set on every versioned response with the API
+	// version that served the request
+	APIVersionHeader = "X-Maya-API-Version"
+
+	// APIVersionV1 serves the annotation based shapes that were served at
+	// /latest before the API was versioned. The volumes are served by frozen
+	// handlers that keep these shapes & their status codes.
+	APIVersionV1 = "v1"
+
+	// APIVersionV2 exposes the typed resources in a RESTful manner
+	APIVersionV2 = "v2"
+)
+
+// v1Routes are the paths served by v1 & the mux paths of their handlers.
+// The paths are relative to the version prefix. Any other path e.g. /vsms/
+// is not found.
+var v1Routes = map[string]string{
+	"/volumes/":       "/v1/volumes/",
+	"/meta-data/":     "/latest/meta-data/",
+	"/api/token":      "/latest/api/token",
+	"/status/":        "/latest/status/",
+	"/agent/":         "/latest/agent/",
+	"/plugins":        "/latest/plugins",
+	"/audit":          "/latest/audit",
+	"/webhooks":       "/latest/webhooks",
+	"/events":         "/latest/events",
+	"/quotas":         "/latest/quotas",
+	"/storageclasses": "/latest/storageclasses",
+}
+
+// v2Routes are the paths of v2 that are served by a different handler than
+// the live v1 shapes at /latest. The paths are relative to the version
+// prefix.
+var v2Routes = map[string]string{
+	"/volumes/": "/vsms/",
+}
+
+// validAPIVersion returns an error if the version can not be aliased as
+// /latest
+func validAPIVersion(version string) error {
+	switch version {
+	case "", APIVersionV1, APIVersionV2:
+		return nil
+	default:
+		return fmt.Errorf("invalid latest api version '%s', must be one of %s, %s", version, APIVersionV1, APIVersionV2)
+	}
+}
+
+// latestAPIVersion returns the API version that is aliased as /latest
+func (s *HTTPServer) latestAPIVersion() string {
+	if s.maya.config.LatestAPIVersion == "" {
+		return APIVersionV1
+	}
+	return s.maya.config.LatestAPIVersion
+}
+
+// apiVersion splits the path into its API version & the path relative to the
+// version prefix. The pinned flag is set if the version is part of the path
+// i.e. the path is not /latest. False is returned if the path is not
+// versioned.
+func (s *HTTPServer) apiVersion(path string) (string, string, bool, bool) {
+	switch {
+	case strings.HasPrefix(path, "/"+APIVersionV1+"/"):
+		return APIVersionV1, strings.TrimPrefix(path, "/"+APIVersionV1), true, true
+	case strings.HasPrefix(path, "/"+APIVersionV2+"/"):
+		return APIVersionV2, strings.TrimPrefix(path, "/"+APIVersionV2), true, true
+	case strings.HasPrefix(path, "/latest/"):
+		return s.latestAPIVersion(), strings.TrimPrefix(path, "/latest"), false, true
+	default:
+		return "", "", false, false
+	}
+}
+
+// routePath returns the mux path of the handler that serves the path of the
+// version. False is returned if the version does not serve the path.
+func routePath(version string, path string, pinned bool) (string, bool) {
+	switch {
+	case version == APIVersionV1 && pinned:
+		for prefix, target := range v1Routes {
+			if strings.HasPrefix(path, prefix) {
+				return target + strings.TrimPrefix(path, prefix), true
+			}
+		}
+		return "", false
+	case version == APIVersionV2:
+		for prefix, target := range v2Routes {
+			if strings.HasPrefix(path, prefix) {
+				return "/latest" + target + strings.TrimPrefix(path, prefix), true
+			}
+		}
+	}
+	return "/latest" + path, true
+}
+
+// versionRouter routes the versioned requests to the handlers that serve
+// their version.
+//
+// NOTE:
+//    /v1 is served as per the v1Routes table. Its volumes are served by the
+// frozen v1 handlers while /latest, if it aliases v1, is served by the live
+// handlers of the same shapes. /v2 is served by the live handlers with the
+// typed resources in place of the v1 shapes. The original request URI is
+// retained, so a forwarded request is served by the same version at the
+// peer.
+func (s *HTTPServer) versionRouter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		version, path, pinned, ok := s.apiVersion(req.URL.Path)
+		if !ok {
+			next.ServeHTTP(resp, req)
+			return
+		}
+
+		resp.Header().Set(APIVersionHeader, version)
+
+		target, ok := routePath(version, path, pinned)
+		if !ok {
+			resp.WriteHeader(404)
+			resp.Write([]byte(fmt.Sprintf("Not found: '%s'", req.URL.Path)))
+			return
+		}
+
+		u := *req.URL
+		u.Path = target
+		u.RawPath = ""
+
+		r := *req
+		r.URL = &u
+
+		next.ServeHTTP(resp, &r)
+	})
+}
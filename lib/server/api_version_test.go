@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestVersionRouter(t *testing.T) {
+	cases := []struct {
+		latest   string
+		path     string
+		version  string
+		expected string
+	}{
+		{"", "/v1/volumes/info/vol1", "v1", "/v1/volumes/info/vol1"},
+		{"", "/v1/agent/self", "v1", "/latest/agent/self"},
+		{"", "/v1/vsms/", "v1", ""},
+		{"", "/latest/vsms/", "v1", "/latest/vsms/"},
+		{"", "/v2/volumes/vol1", "v2", "/latest/vsms/vol1"},
+		{"", "/v2/agent/self", "v2", "/latest/agent/self"},
+		{"", "/latest/volumes/", "v1", "/latest/volumes/"},
+		{"v2", "/latest/volumes/", "v2", "/latest/vsms/"},
+		{"v2", "/v1/volumes/", "v1", "/v1/volumes/"},
+		{"", "/metrics", "", "/metrics"},
+	}
+
+	for _, c := range cases {
+		s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+			mc.LatestAPIVersion = c.latest
+		})
+
+		var path string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+		})
+
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", c.path, nil)
+		s.Server.versionRouter(next).ServeHTTP(resp, req)
+		s.Cleanup()
+
+		if path != c.expected {
+			t.Fatalf("ERR: %s: expected path: %s, got: %s", c.path, c.expected, path)
+		}
+
+		if v := resp.Header().Get(APIVersionHeader); v != c.version {
+			t.Fatalf("ERR: %s: expected version: %s, got: %s", c.path, c.version, v)
+		}
+	}
+}
+
+func TestV1VSMRead(t *testing.T) {
+	defer useFakeVolumes(typedTestVSM())()
+
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	// /v1 is not annotated with the health & has no ETag
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/volumes/info/vol1", nil)
+	s.Server.versionRouter(s.Server.mux).ServeHTTP(resp, req)
+
+	if resp.Code != 200 {
+		t.Fatalf("ERR: expected 200, got: %d %s", resp.Code, resp.Body.String())
+	}
+	if resp.Header().Get("ETag") != "" {
+		t.Fatalf("ERR: expected no ETag, got: %s", resp.Header().Get("ETag"))
+	}
+
+	var pv v1.PersistentVolume
+	if err := json.Unmarshal(resp.Body.Bytes(), &pv); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if !reflect.DeepEqual(pv.Annotations, typedTestVSM().Annotations) {
+		t.Fatalf("ERR: expected the annotations of the provisioner, got: %v", pv.Annotations)
+	}
+
+	// /latest serves the live handlers
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latest/volumes/info/vol1", nil)
+	s.Server.versionRouter(s.Server.mux).ServeHTTP(resp, req)
+
+	if resp.Code != 200 || resp.Header().Get("ETag") == "" || !strings.Contains(resp.Body.String(), mapiv1.HealthAPILbl) {
+		t.Fatalf("ERR: expected the health & an ETag, got: %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestV1VSMAdd(t *testing.T) {
+	defer useFakeVolumes()()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.StrictDecoding = true
+	})
+	defer s.Cleanup()
+
+	// An invalid claim is a 400 & not a 422
+	resp := httptest.NewRecorder()
+	body := `{"metadata": {"name": "vol1", "labels": {"` + string(v1.PVPReplicaCountLbl) + `": "abc"}}}`
+	req, _ := http.NewRequest("POST", "/v1/volumes/", strings.NewReader(body))
+	s.Server.versionRouter(s.Server.mux).ServeHTTP(resp, req)
+
+	if resp.Code != 400 {
+		t.Fatalf("ERR: expected 400, got: %d %s", resp.Code, resp.Body.String())
+	}
+
+	// Unknown fields & content types are accepted
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/v1/volumes/", strings.NewReader(`{"metadata": {"name": "vol1"}, "lables": {}}`))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("If-None-Match", "*")
+	s.Server.versionRouter(s.Server.mux).ServeHTTP(resp, req)
+
+	if resp.Code != 200 {
+		t.Fatalf("ERR: expected 200, got: %d %s", resp.Code, resp.Body.String())
+	}
+
+	// ?region=* is not supported
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/v1/volumes/?region=*", nil)
+	s.Server.versionRouter(s.Server.mux).ServeHTTP(resp, req)
+
+	if resp.Code != 400 {
+		t.Fatalf("ERR: expected 400, got: %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestVersionRouterForward(t *testing.T) {
+	var uri string
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri = r.URL.RequestURI()
+	}))
+	defer peer.Close()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Peers = []*config.Peer{
+			{Region: "west", Addresses: []string{peer.URL}},
+		}
+	})
+	defer s.Cleanup()
+
+	// The peer is sent the path that was received
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v2/volumes/vol1?region=west", nil)
+	req.RequestURI = "/v2/volumes/vol1?region=west"
+	s.Server.versionRouter(s.Server.mux).ServeHTTP(resp, req)
+
+	if uri != "/v2/volumes/vol1?region=west" {
+		t.Fatalf("ERR: expected the versioned path, got: %s", uri)
+	}
+}
+
+func TestInvalidLatestAPIVersion(t *testing.T) {
+	dir, maya := makeMayaServer(t, func(mc *config.MayaConfig) {
+		mc.LatestAPIVersion = "v3"
+	})
+	defer func() {
+		maya.Shutdown()
+		os.RemoveAll(dir)
+	}()
+
+	if _, err := NewHTTPServer(maya, maya.config, nil); err == nil {
+		t.Fatalf("ERR: expected an invalid api version error")
+	}
+}
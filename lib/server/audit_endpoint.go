@@ -0,0 +1,280 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/openebs/mayaserver/lib/audit"
+)
+
+// RequestIDHeader carries the id of a request. An id is generated if the
+// client does not send one.
+const RequestIDHeader = "X-Request-Id"
+
+// ec2MutatingActions are the actions of the EC2 Query API that change the
+// state of a volume. These are audited whatever be the method of the
+// request.
+var ec2MutatingActions = map[string]bool{
+	"CreateVolume": true,
+	"DeleteVolume": true,
+	"AttachVolume": true,
+	"DetachVolume": true,
+}
+
+// auditWriter records the status code of the response of an audited request
+// & writes the audit event once the request is served
+type auditWriter struct {
+	http.ResponseWriter
+
+	s     *HTTPServer
+	event *audit.Event
+	code  int
+}
+
+// WriteHeader records the status code
+func (w *auditWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records a 200 if the status code was not written
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = 200
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// finish writes the audit event with the outcome of the request
+func (w *auditWriter) finish() {
+	if w.code == 0 {
+		w.code = 200
+	}
+
+	w.event.Code = w.code
+	w.event.Outcome = "success"
+	if w.code >= 400 {
+		w.event.Outcome = "failure"
+	}
+
+	if err := w.s.maya.audit.Write(w.event); err != nil {
+		w.s.logger.Printf("[ERR] http: Failed to audit request %s: %v", w.event.RequestID, err)
+	}
+}
+
+// vsmDeletePrefixes are the paths of the VSM deletes that are served via GET
+var vsmDeletePrefixes = []string{
+	"/latest/volumes/delete/",
+	"/" + APIVersionV1 + "/volumes/delete/",
+}
+
+// isVSMDelete returns true if the path deletes a VSM via GET
+func isVSMDelete(path string) bool {
+	for _, prefix := range vsmDeletePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// startAudit starts the audit of a mutating request. Nil is returned if the
+// request is not audited. A request is audited by its operation i.e. by its
+// method, by its path if it is a VSM delete via GET, or by its Action if it
+// is an EC2 Query API call.
+//
+// NOTE:
+//    The request body is buffered to compute its digest & is then restored
+// for the handler. The body itself is never recorded.
+func (s *HTTPServer) startAudit(resp http.ResponseWriter, req *http.Request) *auditWriter {
+	if s.maya.audit == nil {
+		return nil
+	}
+
+	// The EC2 Query API is served at the root
+	isEC2 := req.URL.Path == "/"
+	isRead := req.Method == "GET" || req.Method == "HEAD" || req.Method == "OPTIONS"
+	if isRead && !isEC2 && !isVSMDelete(req.URL.Path) {
+		return nil
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+	}
+
+	var ec2Params url.Values
+	if isEC2 {
+		ec2Params = ec2AuditParams(req, body)
+		if !ec2MutatingActions[ec2Params.Get("Action")] {
+			return nil
+		}
+	}
+
+	reqID := req.Header.Get(RequestIDHeader)
+	if reqID == "" {
+		reqID, _ = randomHex(16)
+	}
+	resp.Header().Set(RequestIDHeader, reqID)
+
+	e := &audit.Event{
+		Time:      time.Now().UTC(),
+		Principal: s.principal(req),
+		ClientIP:  clientIP(req),
+		RequestID: reqID,
+		Method:    req.Method,
+		Path:      strings.SplitN(requestURI(req), "?", 2)[0],
+	}
+
+	if isEC2 {
+		e.Action = ec2Params.Get("Action")
+		e.Volume = ec2Params.Get("VolumeId")
+	} else {
+		e.Volume = auditVolumeName(req, body)
+	}
+
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		e.BodyDigest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	return &auditWriter{ResponseWriter: resp, s: s, event: e}
+}
+
+// errReader returns the error of reading the original request body once the
+// buffered body is read
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// principal identifies the caller. The holder of the management token is
+// the management principal, a TLS client is identified by its certificate.
+func (s *HTTPServer) principal(req *http.Request) string {
+	if req.Header.Get(MayaTokenHeader) != "" && s.checkACL(req) == nil {
+		return "management"
+	}
+
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		return req.TLS.PeerCertificates[0].Subject.CommonName
+	}
+
+	return "anonymous"
+}
+
+// clientIP returns the IP address of the client
+func clientIP(req *http.Request) string {
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
+
+// ec2AuditParams returns the params of an EC2 Query API call. These are
+// either a part of the query or of the url encoded body.
+func ec2AuditParams(req *http.Request, body []byte) url.Values {
+	params := req.URL.Query()
+
+	cType := req.Header.Get("Content-Type")
+	if len(body) == 0 || (cType != "" && !strings.HasPrefix(cType, "application/x-www-form-urlencoded")) {
+		return params
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return params
+	}
+	for k, vv := range form {
+		params[k] = append(vv, params[k]...)
+	}
+	return params
+}
+
+// auditVolumeName returns the name of the VSM the request is about. The name
+// is either a part of the path or of the claim in the body.
+func auditVolumeName(req *http.Request, body []byte) string {
+	for _, prefix := range append(vsmDeletePrefixes, "/latest/vsms/") {
+		if name := strings.TrimPrefix(req.URL.Path, prefix); name != req.URL.Path && name != "" {
+			return name
+		}
+	}
+
+	if len(body) == 0 {
+		return ""
+	}
+
+	// JSON is a subset of YAML
+	j, err := yaml.YAMLToJSON(body)
+	if err != nil {
+		return ""
+	}
+
+	var claim struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(j, &claim); err != nil {
+		return ""
+	}
+
+	return claim.Metadata.Name
+}
+
+// AuditRequest is a http handler implementation. It queries the audit log.
+//
+// NOTE:
+//    GET /latest/audit?volume=<name>&since=<RFC3339>&until=<RFC3339>
+func (s *HTTPServer) AuditRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if err := s.checkACL(req); err != nil {
+		return nil, err
+	}
+
+	if s.maya.audit == nil {
+		return nil, CodedError(404, "Audit is not enabled")
+	}
+
+	q := audit.Query{
+		Volume: req.URL.Query().Get("volume"),
+	}
+
+	for param, t := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		value := req.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, CodedError(400, fmt.Sprintf("Invalid %s '%s', expected RFC3339 e.g. 2017-09-01T10:00:00Z", param, value))
+		}
+		*t = parsed
+	}
+
+	return s.maya.audit.Query(q)
+}
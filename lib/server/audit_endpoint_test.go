@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openebs/mayaserver/lib/audit"
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestAuditRequest(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "s3cr3t"}
+		mc.Audit = &config.Audit{Enabled: true}
+	})
+	defer s.Cleanup()
+
+	// An invalid claim is audited as a failure
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(`{"metadata": {"name": "Vol1"}}`))
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	req.Header.Set(RequestIDHeader, "req-1")
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.VSMSpecificRequest)(resp, req)
+
+	if resp.Code != 422 {
+		t.Fatalf("ERR: http resp code, expected: 422, got: %v", resp.Code)
+	}
+
+	// A read is not audited
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latest/vsms/vol2", nil)
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.TypedVSMRequest)(resp, req)
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/latest/vsms/vol2", nil)
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.TypedVSMRequest)(resp, req)
+
+	if resp.Header().Get(RequestIDHeader) == "" {
+		t.Fatalf("ERR: expected a generated request id")
+	}
+
+	// The audit log requires the management token
+	req, _ = http.NewRequest("GET", "/latest/audit", nil)
+	if _, err := s.Server.AuditRequest(httptest.NewRecorder(), req); err == nil || err.Error() != ErrPermissionDenied {
+		t.Fatalf("ERR: expected: %v, got: %v", ErrPermissionDenied, err)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/audit?volume=Vol1", nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	out, err := s.Server.AuditRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	events := out.([]*audit.Event)
+	if len(events) != 1 {
+		t.Fatalf("ERR: expected 1 event, got: %+v", events)
+	}
+
+	e := events[0]
+	if e.RequestID != "req-1" || e.Principal != "management" || e.Method != "POST" ||
+		e.Path != "/latest/volumes/" || e.Code != 422 || e.Outcome != "failure" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+	if !strings.HasPrefix(e.BodyDigest, "sha256:") || strings.Contains(e.BodyDigest, "Vol1") {
+		t.Fatalf("ERR: expected a digest of the body, got: %s", e.BodyDigest)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/audit", nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	out, _ = s.Server.AuditRequest(httptest.NewRecorder(), req)
+	if events := out.([]*audit.Event); len(events) != 2 || events[1].Volume != "vol2" || events[1].Principal != "anonymous" {
+		t.Fatalf("ERR: expected the POST & the DELETE, got: %+v", events)
+	}
+
+	since := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	req, _ = http.NewRequest("GET", "/latest/audit?since="+since, nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	out, _ = s.Server.AuditRequest(httptest.NewRecorder(), req)
+	if events := out.([]*audit.Event); len(events) != 0 {
+		t.Fatalf("ERR: expected no events, got: %+v", events)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/audit?until=yesterday", nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	if _, err := s.Server.AuditRequest(httptest.NewRecorder(), req); err == nil || err.(HTTPCodedError).Code() != 400 {
+		t.Fatalf("ERR: expected 400, got: %v", err)
+	}
+}
+
+func TestAuditVSMDeleteViaGet(t *testing.T) {
+	defer useFakeVolumes(typedTestVSM())()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Audit = &config.Audit{Enabled: true}
+	})
+	defer s.Cleanup()
+
+	// The deletes are audited while the reads are not
+	for _, path := range []string{"/latest/volumes/info/vol1", "/latest/volumes/delete/vol1", "/v1/volumes/delete/vol2"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		s.Server.versionRouter(s.Server.mux).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	events, err := s.Maya.audit.Query(audit.Query{})
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("ERR: expected 2 events, got: %+v", events)
+	}
+
+	if e := events[0]; e.Volume != "vol1" || e.Method != "GET" || e.Path != "/latest/volumes/delete/vol1" || e.Outcome != "success" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+
+	if e := events[1]; e.Volume != "vol2" || e.Path != "/v1/volumes/delete/vol2" || e.Outcome != "failure" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+}
+
+func TestAuditEC2Request(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Audit = &config.Audit{Enabled: true}
+	})
+	defer s.Cleanup()
+
+	// A mutating action is audited even if it is a GET, while a read is not
+	// audited even if it is a POST
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "/?Action=DeleteVolume&VolumeId=vol-1", ""},
+		{"GET", "/?Action=DescribeVolumes", ""},
+		{"POST", "/", "Action=DescribeVolumes"},
+		{"POST", "/", "Action=AttachVolume&VolumeId=vol-2&InstanceId=i-1&Device=%2Fdev%2Fsdf"},
+	}
+
+	for _, r := range requests {
+		req, _ := http.NewRequest(r.method, r.path, bytes.NewBufferString(r.body))
+		if r.body != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		s.Server.wrap(RequestCounter, RequestDuration, s.Server.EC2QueryRequest)(httptest.NewRecorder(), req)
+	}
+
+	events, err := s.Maya.audit.Query(audit.Query{})
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("ERR: expected 2 events, got: %+v", events)
+	}
+
+	if e := events[0]; e.Action != "DeleteVolume" || e.Volume != "vol-1" || e.Method != "GET" || e.Path != "/" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+
+	if e := events[1]; e.Action != "AttachVolume" || e.Volume != "vol-2" || e.Method != "POST" || e.BodyDigest == "" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+}
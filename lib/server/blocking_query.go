@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultQueryTime is the wait of a blocking query that does not set
+	// ?wait
+	defaultQueryTime = 5 * time.Minute
+
+	// maxQueryTime is the maximum wait of a blocking query
+	maxQueryTime = 10 * time.Minute
+)
+
+// vsmIndex is a counter that is bumped on every change of the VSMs that is
+// made or observed by this maya api server. Blocking queries wait on it.
+type vsmIndex struct {
+	lock    sync.Mutex
+	index   uint64
+	changed chan struct{}
+}
+
+// newVSMIndex returns an index that starts at 1. Hence a query with index 0
+// never blocks.
+func newVSMIndex() *vsmIndex {
+	return &vsmIndex{index: 1, changed: make(chan struct{})}
+}
+
+// current returns the index along with a channel that is closed once the
+// index is bumped
+func (vi *vsmIndex) current() (uint64, <-chan struct{}) {
+	vi.lock.Lock()
+	defer vi.lock.Unlock()
+
+	return vi.index, vi.changed
+}
+
+// bump increments the index & wakes up the blocked queries
+func (vi *vsmIndex) bump() {
+	vi.lock.Lock()
+	defer vi.lock.Unlock()
+
+	vi.index++
+	close(vi.changed)
+	vi.changed = make(chan struct{})
+}
+
+// parseWait is used to parse the ?wait and ?index query params. A zero index
+// means the query does not block.
+func parseWait(req *http.Request) (uint64, time.Duration, error) {
+	query := req.URL.Query()
+
+	var index uint64
+	if idx := query.Get("index"); idx != "" {
+		i, err := strconv.ParseUint(idx, 10, 64)
+		if err != nil {
+			return 0, 0, CodedError(400, fmt.Sprintf("Invalid index '%s'", idx))
+		}
+		index = i
+	}
+
+	wait := defaultQueryTime
+	if w := query.Get("wait"); w != "" {
+		dur, err := time.ParseDuration(w)
+		if err != nil || dur < 0 {
+			return 0, 0, CodedError(400, fmt.Sprintf("Invalid wait time '%s'", w))
+		}
+		wait = dur
+	}
+	if wait > maxQueryTime {
+		wait = maxQueryTime
+	}
+
+	return index, wait, nil
+}
+
+// blockOnVSMIndex blocks a query that sets ?index till the VSM index moves
+// past it, the wait elapses or the client goes away. The index the query is
+// served at is set as the X-Maya-Index header.
+//
+// NOTE:
+//    A client watches the VSMs by passing the X-Maya-Index of the previous
+// response as ?index of the next query.
+func (s *HTTPServer) blockOnVSMIndex(resp http.ResponseWriter, req *http.Request) error {
+	minIndex, wait, err := parseWait(req)
+	if err != nil {
+		return err
+	}
+
+	index, changed := s.maya.vsmIndex.current()
+
+	// An index past the current one e.g. from before a restart will never
+	// be reached, hence the query is served right away
+	if minIndex > index {
+		setIndex(resp, index)
+		return nil
+	}
+
+	if minIndex > 0 && index == minIndex {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		for index == minIndex {
+			select {
+			case <-changed:
+				index, changed = s.maya.vsmIndex.current()
+			case <-timer.C:
+				setIndex(resp, index)
+				return nil
+			case <-req.Context().Done():
+				return req.Context().Err()
+			case <-s.maya.shutdownCh:
+				return CodedError(503, "Maya api server is shutting down")
+			}
+		}
+	}
+
+	setIndex(resp, index)
+	return nil
+}
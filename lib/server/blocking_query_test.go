@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseWait(t *testing.T) {
+	cases := []struct {
+		query string
+		index uint64
+		wait  time.Duration
+		err   bool
+	}{
+		{"", 0, defaultQueryTime, false},
+		{"index=5&wait=10s", 5, 10 * time.Second, false},
+		{"index=5&wait=1h", 5, maxQueryTime, false},
+		{"index=abc", 0, 0, true},
+		{"wait=soon", 0, 0, true},
+		{"wait=-1s", 0, 0, true},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest("GET", "/latest/vsms/?"+c.query, nil)
+		index, wait, err := parseWait(req)
+		if c.err {
+			if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 400 {
+				t.Fatalf("ERR: %q expected a 400 coded error, got: %v", c.query, err)
+			}
+			continue
+		}
+		if err != nil || index != c.index || wait != c.wait {
+			t.Fatalf("ERR: %q got index %d, wait %v, err %v", c.query, index, wait, err)
+		}
+	}
+}
+
+func TestBlockOnVSMIndex(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	index, _ := s.Maya.vsmIndex.current()
+
+	// A query without an index does not block
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/latest/vsms/", nil)
+	if err := s.Server.blockOnVSMIndex(resp, req); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if resp.Header().Get("X-Maya-Index") != "1" || index != 1 {
+		t.Fatalf("ERR: expected index 1, got: %s", resp.Header().Get("X-Maya-Index"))
+	}
+
+	// A query at the current index returns once a VSM is added
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.Maya.vsmAdded("vol1", nil)
+	}()
+
+	start := time.Now()
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latest/vsms/?index=1&wait=5s", nil)
+	if err := s.Server.blockOnVSMIndex(resp, req); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if resp.Header().Get("X-Maya-Index") != "2" {
+		t.Fatalf("ERR: expected index 2, got: %s", resp.Header().Get("X-Maya-Index"))
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Fatalf("ERR: query did not return on the change")
+	}
+
+	// A failed add does not move the index & the wait elapses
+	s.Maya.vsmAdded("vol2", context.DeadlineExceeded)
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latest/vsms/?index=2&wait=50ms", nil)
+	if err := s.Server.blockOnVSMIndex(resp, req); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if resp.Header().Get("X-Maya-Index") != "2" {
+		t.Fatalf("ERR: expected index 2, got: %s", resp.Header().Get("X-Maya-Index"))
+	}
+
+	// A query past the current index e.g. from before a restart does not block
+	start = time.Now()
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latest/vsms/?index=10&wait=5s", nil)
+	if err := s.Server.blockOnVSMIndex(resp, req); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if resp.Header().Get("X-Maya-Index") != "2" || time.Since(start) > 4*time.Second {
+		t.Fatalf("ERR: expected index 2 right away, got: %s", resp.Header().Get("X-Maya-Index"))
+	}
+
+	// The query returns once the client goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ = http.NewRequest("GET", "/latest/vsms/?index=2", nil)
+	if err := s.Server.blockOnVSMIndex(httptest.NewRecorder(), req.WithContext(ctx)); err != context.Canceled {
+		t.Fatalf("ERR: expected the context error, got: %v", err)
+	}
+}
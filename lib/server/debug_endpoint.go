@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/ugorji/go/codec"
+)
+
+// RuntimeVars are the runtime stats of maya api server
+type RuntimeVars struct {
+	GoVersion    string            `json:"goVersion"`
+	GOOS         string            `json:"goos"`
+	GOARCH       string            `json:"goarch"`
+	NumCPU       int               `json:"numCPU"`
+	GOMAXPROCS   int               `json:"gomaxprocs"`
+	NumGoroutine int               `json:"numGoroutine"`
+	NumCgoCall   int64             `json:"numCgoCall"`
+	Cmdline      []string          `json:"cmdline"`
+	Uptime       string            `json:"uptime"`
+	MemStats     *runtime.MemStats `json:"memstats"`
+}
+
+// validDebugConfig returns an error if the debug endpoints are enabled but
+// would be served without any protection i.e. neither the acl is enabled nor
+// the admin port is bound to a loopback address.
+//
+// NOTE:
+//    The admin address defaults to bind_addr e.g. 0.0.0.0. Hence an admin
+// port by itself does not protect the debug endpoints.
+func validDebugConfig(config *config.MayaConfig) error {
+	if !config.EnableDebug {
+		return nil
+	}
+
+	if acl := config.ACL; acl != nil && acl.Enabled {
+		return nil
+	}
+
+	if config.NormalizedAddrs != nil && isLoopbackAddr(config.NormalizedAddrs.Admin) {
+		return nil
+	}
+
+	return fmt.Errorf("enable_debug requires either acl to be enabled or an admin port bound to a loopback address")
+}
+
+// isLoopbackAddr returns true if the host of the host:port address is a
+// loopback address
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// registerDebugHandlers mounts the pprof handlers, the goroutine dump & the
+// runtime stats under /debug/. These are guarded by the management token.
+func (s *HTTPServer) registerDebugHandlers(mux *http.ServeMux) {
+	mux.Handle("/debug/pprof/", s.aclHandler(http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", s.aclHandler(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", s.aclHandler(http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", s.aclHandler(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", s.aclHandler(http.HandlerFunc(pprof.Trace)))
+
+	mux.Handle("/debug/goroutines", s.aclHandler(http.HandlerFunc(s.debugGoroutines)))
+	mux.Handle("/debug/vars", s.aclHandler(http.HandlerFunc(s.debugVars)))
+}
+
+// debugGoroutines dumps the stack traces of all the goroutines
+func (s *HTTPServer) debugGoroutines(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rpprof.Lookup("goroutine").WriteTo(resp, 2)
+}
+
+// debugVars writes the runtime stats as JSON
+func (s *HTTPServer) debugVars(resp http.ResponseWriter, req *http.Request) {
+	memStats := &runtime.MemStats{}
+	runtime.ReadMemStats(memStats)
+
+	vars := &RuntimeVars{
+		GoVersion:    runtime.Version(),
+		GOOS:         runtime.GOOS,
+		GOARCH:       runtime.GOARCH,
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		NumGoroutine: runtime.NumGoroutine(),
+		NumCgoCall:   runtime.NumCgoCall(),
+		Cmdline:      os.Args,
+		Uptime:       (time.Since(s.maya.startTime) / time.Second * time.Second).String(),
+		MemStats:     memStats,
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if err := codec.NewEncoder(resp, jsonHandlePretty).Encode(vars); err != nil {
+		s.logger.Printf("[ERR] http: Request %v, error: %v", req.URL, err)
+	}
+}
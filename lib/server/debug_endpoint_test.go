@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestDebugDisabled(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/debug/vars", nil)
+
+	s.Server.mux.ServeHTTP(resp, req)
+
+	if resp.Code != 404 {
+		t.Fatalf("ERR: http resp code, expected: 404, got: %v", resp.Code)
+	}
+}
+
+func TestDebugACL(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.EnableDebug = true
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "s3cr3t"}
+	})
+	defer s.Cleanup()
+
+	for _, path := range []string{"/debug/vars", "/debug/goroutines", "/debug/pprof/"} {
+		cases := map[string]int{
+			"":       403,
+			"oddy":   403,
+			"s3cr3t": 200,
+		}
+
+		for token, code := range cases {
+			resp := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+			if token != "" {
+				req.Header.Set(MayaTokenHeader, token)
+			}
+
+			s.Server.mux.ServeHTTP(resp, req)
+
+			if resp.Code != code {
+				t.Fatalf("ERR: %s: token %q: expected: %d, got: %d", path, token, code, resp.Code)
+			}
+		}
+	}
+}
+
+func TestDebugAdminListener(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.EnableDebug = true
+		mc.Ports.Admin = getPort()
+	})
+	defer s.Cleanup()
+
+	if s.Server.adminMux == nil || s.Server.adminListener == nil {
+		t.Fatalf("ERR: expected the admin listener to be started")
+	}
+
+	// The debug endpoints are served only by the admin listener
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/debug/vars", nil)
+	s.Server.mux.ServeHTTP(resp, req)
+
+	if resp.Code != 404 {
+		t.Fatalf("ERR: http resp code, expected: 404, got: %v", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	s.Server.adminMux.ServeHTTP(resp, req)
+
+	if resp.Code != 200 {
+		t.Fatalf("ERR: http resp code, expected: 200, got: %v", resp.Code)
+	}
+
+	if ct := resp.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("ERR: content type, expected: application/json, got: %s", ct)
+	}
+}
+
+func TestDebugWithoutProtection(t *testing.T) {
+	dir, maya := makeMayaServer(t, func(mc *config.MayaConfig) {
+		mc.EnableDebug = true
+	})
+	defer func() {
+		maya.Shutdown()
+		os.RemoveAll(dir)
+	}()
+
+	if _, err := NewHTTPServer(maya, maya.config, nil); err == nil {
+		t.Fatalf("ERR: expected an error for debug endpoints without acl & admin port")
+	}
+}
+
+func TestDebugAdminNotLoopback(t *testing.T) {
+	// The admin address defaults to bind_addr
+	dir, maya := makeMayaServer(t, func(mc *config.MayaConfig) {
+		mc.EnableDebug = true
+		mc.BindAddr = "0.0.0.0"
+		mc.Ports.Admin = getPort()
+	})
+	defer func() {
+		maya.Shutdown()
+		os.RemoveAll(dir)
+	}()
+
+	if _, err := NewHTTPServer(maya, maya.config, nil); err == nil {
+		t.Fatalf("ERR: expected an error for debug endpoints on a non loopback admin address")
+	}
+}
@@ -0,0 +1,514 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+const (
+	// EC2XMLNS is the namespace of the EC2 Query API responses
+	EC2XMLNS = "http://ec2.amazonaws.com/doc/2016-11-15/"
+
+	// ec2VolumeType is the only volume type reported to EBS clients
+	ec2VolumeType = "standard"
+
+	// gib is the unit of the EBS volume size
+	gib = 1024 * 1024 * 1024
+)
+
+// ec2Error is an error of the EC2 Query API. It is a HTTPCodedError whose
+// message is the XML encoded error response.
+type ec2Error struct {
+	code      int
+	ErrCode   string
+	Message   string
+	RequestID string
+}
+
+func (e *ec2Error) Code() int {
+	return e.code
+}
+
+func (e *ec2Error) Error() string {
+	out, _ := xml.Marshal(struct {
+		XMLName   xml.Name `xml:"Response"`
+		Code      string   `xml:"Errors>Error>Code"`
+		Message   string   `xml:"Errors>Error>Message"`
+		RequestID string   `xml:"RequestID"`
+	}{
+		Code:      e.ErrCode,
+		Message:   e.Message,
+		RequestID: e.RequestID,
+	})
+	return xml.Header + string(out)
+}
+
+// ec2Volume is an EBS volume as per the EC2 Query API
+type ec2Volume struct {
+	VolumeID         string                `xml:"volumeId"`
+	Size             int64                 `xml:"size"`
+	SnapshotID       string                `xml:"snapshotId"`
+	AvailabilityZone string                `xml:"availabilityZone"`
+	Status           string                `xml:"status"`
+	CreateTime       string                `xml:"createTime,omitempty"`
+	Attachments      []ec2VolumeAttachment `xml:"attachmentSet>item"`
+	VolumeType       string                `xml:"volumeType"`
+	Encrypted        bool                  `xml:"encrypted"`
+}
+
+// ec2VolumeAttachment is the attachment of an EBS volume as reported by
+// DescribeVolumes
+type ec2VolumeAttachment struct {
+	VolumeID            string `xml:"volumeId"`
+	InstanceID          string `xml:"instanceId"`
+	Device              string `xml:"device"`
+	Status              string `xml:"status"`
+	AttachTime          string `xml:"attachTime"`
+	DeleteOnTermination bool   `xml:"deleteOnTermination"`
+}
+
+// ec2AttachmentStore keeps the attachments of the EBS volumes in memory.
+// Maya has no notion of a VSM being attached, hence the attachments are lost
+// when the server restarts.
+type ec2AttachmentStore struct {
+	sync.Mutex
+	byVolume map[string]ec2VolumeAttachment
+}
+
+func newEC2AttachmentStore() *ec2AttachmentStore {
+	return &ec2AttachmentStore{
+		byVolume: map[string]ec2VolumeAttachment{},
+	}
+}
+
+// attach records the attachment of a volume. It fails if the volume is
+// attached already.
+func (as *ec2AttachmentStore) attach(a ec2VolumeAttachment) error {
+	as.Lock()
+	defer as.Unlock()
+
+	if _, ok := as.byVolume[a.VolumeID]; ok {
+		return &ec2Error{code: 400, ErrCode: "VolumeInUse", Message: fmt.Sprintf("The volume '%s' is already attached", a.VolumeID)}
+	}
+	as.byVolume[a.VolumeID] = a
+	return nil
+}
+
+// detach removes the attachment of a volume. It fails if the volume is not
+// attached.
+func (as *ec2AttachmentStore) detach(volumeID string) (ec2VolumeAttachment, error) {
+	as.Lock()
+	defer as.Unlock()
+
+	a, ok := as.byVolume[volumeID]
+	if !ok {
+		return a, &ec2Error{code: 400, ErrCode: "IncorrectState", Message: fmt.Sprintf("The volume '%s' is not attached", volumeID)}
+	}
+	delete(as.byVolume, volumeID)
+	return a, nil
+}
+
+// get returns the attachment of a volume if any
+func (as *ec2AttachmentStore) get(volumeID string) (ec2VolumeAttachment, bool) {
+	as.Lock()
+	defer as.Unlock()
+
+	a, ok := as.byVolume[volumeID]
+	return a, ok
+}
+
+// ec2Attachment is the attachment of an EBS volume to an instance
+type ec2Attachment struct {
+	XMLName    xml.Name
+	Xmlns      string `xml:"xmlns,attr"`
+	RequestID  string `xml:"requestId"`
+	VolumeID   string `xml:"volumeId"`
+	InstanceID string `xml:"instanceId"`
+	Device     string `xml:"device"`
+	Status     string `xml:"status"`
+	AttachTime string `xml:"attachTime"`
+}
+
+// EC2QueryRequest is a http handler implementation. It emulates a subset of
+// the EC2 Query API i.e. the EBS volume calls on top of the persistent
+// volume provisioner. The supported actions are CreateVolume,
+// DescribeVolumes, DeleteVolume, AttachVolume & DetachVolume. The snapshot
+// actions are answered with UnsupportedOperation as the provisioner can not
+// snapshot a VSM.
+//
+// NOTE:
+//    Only the root path is served & only if the Action param is set. Any
+// other path that does not match a route is not found.
+func (s *HTTPServer) EC2QueryRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.URL.Path != "/" {
+		return nil, CodedError(404, fmt.Sprintf("Not found: '%s'", req.URL.Path))
+	}
+
+	if req.Method != "GET" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+
+	action := req.Form.Get("Action")
+	if action == "" {
+		return nil, CodedError(404, fmt.Sprintf("Not found: '%s'", req.URL.Path))
+	}
+
+	s.logger.Printf("[DEBUG] http: Processing EC2 %s request", action)
+
+	requestID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	// Errors as well as responses are XML
+	resp.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+
+	var out interface{}
+	switch action {
+	case "CreateVolume":
+		out, err = s.ec2CreateVolume(req, requestID)
+	case "DescribeVolumes":
+		out, err = s.ec2DescribeVolumes(req, requestID)
+	case "DeleteVolume":
+		out, err = s.ec2DeleteVolume(req, requestID)
+	case "AttachVolume":
+		out, err = s.ec2AttachVolume(req, requestID)
+	case "DetachVolume":
+		out, err = s.ec2DetachVolume(req, requestID)
+	case "CreateSnapshot", "DeleteSnapshot", "DescribeSnapshots":
+		err = &ec2Error{code: 400, ErrCode: "UnsupportedOperation", Message: fmt.Sprintf("The action '%s' is not supported as volumes can not be snapshotted", action)}
+	default:
+		err = &ec2Error{code: 400, ErrCode: "InvalidAction", Message: fmt.Sprintf("The action '%s' is not valid for this web service", action)}
+	}
+
+	if err != nil {
+		return nil, toEC2Error(err, requestID)
+	}
+
+	body, err := xml.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+
+	return xmlResponse(xml.Header + string(body)), nil
+}
+
+// ec2CreateVolume creates a VSM whose name is a generated EBS volume id. The
+// claim passes through the storage classes, the admission policies, the
+// validation & the quotas like any other claim.
+func (s *HTTPServer) ec2CreateVolume(req *http.Request, requestID string) (interface{}, error) {
+	size, err := strconv.ParseInt(req.Form.Get("Size"), 10, 64)
+	if err != nil || size < 1 {
+		return nil, &ec2Error{code: 400, ErrCode: "InvalidParameterValue", Message: "Size must be a positive number of GiBs"}
+	}
+
+	zone := s.metaAvailabilityZone()
+	if az := req.Form.Get("AvailabilityZone"); az != "" && az != zone {
+		return nil, &ec2Error{code: 400, ErrCode: "InvalidZone.NotFound", Message: fmt.Sprintf("The zone '%s' does not exist", az)}
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	pvc := &v1.PersistentVolumeClaim{}
+	pvc.Name = "vol-" + id
+	pvc.Labels = map[string]string{
+		string(v1.PVPStorageSizeLbl): fmt.Sprintf("%dGi", size),
+	}
+
+	if err := s.admitClaim(pvc); err != nil {
+		return nil, ec2AdmissionError(err, &ec2Error{code: 403, ErrCode: "UnauthorizedOperation"})
+	}
+
+	unlock, err := s.admitVSMAdd(req, pvc)
+	if err != nil {
+		return nil, ec2AdmissionError(err, &ec2Error{code: 400, ErrCode: "VolumeLimitExceeded"})
+	}
+
+	_, err = addVSM(pvc)
+	s.maya.vsmAdded(pvc.Name, err)
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return struct {
+		XMLName   xml.Name `xml:"CreateVolumeResponse"`
+		Xmlns     string   `xml:"xmlns,attr"`
+		RequestID string   `xml:"requestId"`
+		ec2Volume
+	}{
+		Xmlns:     EC2XMLNS,
+		RequestID: requestID,
+		ec2Volume: ec2Volume{
+			VolumeID:         pvc.Name,
+			Size:             size,
+			AvailabilityZone: zone,
+			Status:           "creating",
+			CreateTime:       time.Now().UTC().Format(time.RFC3339),
+			VolumeType:       ec2VolumeType,
+		},
+	}, nil
+}
+
+// ec2DescribeVolumes lists the VSMs as EBS volumes. The VSMs can be
+// filtered by their ids via VolumeId.N params.
+func (s *HTTPServer) ec2DescribeVolumes(req *http.Request, requestID string) (interface{}, error) {
+	pvl, err := listVSMs()
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := map[string]ec2Volume{}
+	var ids []string
+	for i := range pvl.Items {
+		vol := s.toEC2Volume(&pvl.Items[i])
+		volumes[vol.VolumeID] = vol
+		ids = append(ids, vol.VolumeID)
+	}
+
+	if filter := ec2ListParam(req, "VolumeId"); len(filter) > 0 {
+		for _, id := range filter {
+			if _, ok := volumes[id]; !ok {
+				return nil, &ec2Error{code: 400, ErrCode: "InvalidVolume.NotFound", Message: fmt.Sprintf("The volume '%s' does not exist", id)}
+			}
+		}
+		ids = filter
+	}
+
+	items := []ec2Volume{}
+	for _, id := range ids {
+		items = append(items, volumes[id])
+	}
+
+	return struct {
+		XMLName   xml.Name    `xml:"DescribeVolumesResponse"`
+		Xmlns     string      `xml:"xmlns,attr"`
+		RequestID string      `xml:"requestId"`
+		Items     []ec2Volume `xml:"volumeSet>item"`
+	}{
+		Xmlns:     EC2XMLNS,
+		RequestID: requestID,
+		Items:     items,
+	}, nil
+}
+
+// ec2DeleteVolume deletes the VSM with the given volume id. An attached
+// volume can not be deleted.
+func (s *HTTPServer) ec2DeleteVolume(req *http.Request, requestID string) (interface{}, error) {
+	id, err := ec2RequiredParam(req, "VolumeId")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.ec2Attachments.get(id); ok {
+		return nil, &ec2Error{code: 400, ErrCode: "VolumeInUse", Message: fmt.Sprintf("The volume '%s' is attached", id)}
+	}
+
+	s.vsmLock.Lock()
+	err = deleteVSM(id)
+	s.maya.vsmDeleted(id, err)
+	s.vsmLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return struct {
+		XMLName   xml.Name `xml:"DeleteVolumeResponse"`
+		Xmlns     string   `xml:"xmlns,attr"`
+		RequestID string   `xml:"requestId"`
+		Return    bool     `xml:"return"`
+	}{
+		Xmlns:     EC2XMLNS,
+		RequestID: requestID,
+		Return:    true,
+	}, nil
+}
+
+// ec2AttachVolume records the attachment of a volume to this instance. The
+// iSCSI login is done by the instance itself, hence the attachment is
+// reported as attached by DescribeVolumes right away.
+func (s *HTTPServer) ec2AttachVolume(req *http.Request, requestID string) (interface{}, error) {
+	id, err := ec2RequiredParam(req, "VolumeId")
+	if err != nil {
+		return nil, err
+	}
+
+	instanceID, err := ec2RequiredParam(req, "InstanceId")
+	if err != nil {
+		return nil, err
+	}
+	if instanceID != s.metaInstanceID() {
+		return nil, &ec2Error{code: 400, ErrCode: "InvalidInstanceID.NotFound", Message: fmt.Sprintf("The instance ID '%s' does not exist", instanceID)}
+	}
+
+	device, err := ec2RequiredParam(req, "Device")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := readVSM(id); err != nil {
+		return nil, err
+	}
+
+	a := ec2VolumeAttachment{
+		VolumeID:   id,
+		InstanceID: instanceID,
+		Device:     device,
+		Status:     "attached",
+		AttachTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := s.ec2Attachments.attach(a); err != nil {
+		return nil, err
+	}
+
+	return toEC2Attachment("AttachVolumeResponse", requestID, a, "attaching"), nil
+}
+
+// ec2DetachVolume removes the attachment of a volume. The volume is
+// reported as available by DescribeVolumes right away.
+func (s *HTTPServer) ec2DetachVolume(req *http.Request, requestID string) (interface{}, error) {
+	id, err := ec2RequiredParam(req, "VolumeId")
+	if err != nil {
+		return nil, err
+	}
+
+	if instanceID := req.Form.Get("InstanceId"); instanceID != "" && instanceID != s.metaInstanceID() {
+		return nil, &ec2Error{code: 400, ErrCode: "InvalidInstanceID.NotFound", Message: fmt.Sprintf("The instance ID '%s' does not exist", instanceID)}
+	}
+
+	a, err := s.ec2Attachments.detach(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toEC2Attachment("DetachVolumeResponse", requestID, a, "detaching"), nil
+}
+
+// toEC2Attachment builds the response of an attach or a detach call
+func toEC2Attachment(response, requestID string, a ec2VolumeAttachment, status string) ec2Attachment {
+	return ec2Attachment{
+		XMLName:    xml.Name{Local: response},
+		Xmlns:      EC2XMLNS,
+		RequestID:  requestID,
+		VolumeID:   a.VolumeID,
+		InstanceID: a.InstanceID,
+		Device:     a.Device,
+		Status:     status,
+		AttachTime: a.AttachTime,
+	}
+}
+
+// toEC2Volume converts a VSM into an EBS volume
+func (s *HTTPServer) toEC2Volume(pv *v1.PersistentVolume) ec2Volume {
+	vsm := mapiv1.FromPersistentVolume(pv)
+
+	vol := ec2Volume{
+		VolumeID:         vsm.Name,
+		Size:             (vsm.Spec.CapacityBytes + gib - 1) / gib,
+		AvailabilityZone: s.metaAvailabilityZone(),
+		VolumeType:       ec2VolumeType,
+	}
+
+	if !vsm.CreationTimestamp.IsZero() {
+		vol.CreateTime = vsm.CreationTimestamp.UTC().Format(time.RFC3339)
+	}
+
+	// An offline VSM can not be told apart from one that is still coming up.
+	// EBS clients poll till the volume is available.
+	switch vsm.Status.Health {
+	case mapiv1.Healthy, mapiv1.Degraded:
+		vol.Status = "available"
+	default:
+		vol.Status = "creating"
+	}
+
+	if a, ok := s.ec2Attachments.get(vol.VolumeID); ok {
+		vol.Status = "in-use"
+		vol.Attachments = []ec2VolumeAttachment{a}
+	}
+
+	return vol
+}
+
+// ec2RequiredParam returns the value of the given param or a MissingParameter
+// error if it is not set
+func ec2RequiredParam(req *http.Request, name string) (string, error) {
+	value := req.Form.Get(name)
+	if value == "" {
+		return "", &ec2Error{code: 400, ErrCode: "MissingParameter", Message: "The request must contain the parameter " + name}
+	}
+	return value, nil
+}
+
+// ec2ListParam returns the values of a list param i.e. <name>.1, <name>.2 ...
+func ec2ListParam(req *http.Request, name string) []string {
+	var values []string
+	for i := 1; ; i++ {
+		value := req.Form.Get(name + "." + strconv.Itoa(i))
+		if value == "" {
+			return values
+		}
+		values = append(values, value)
+	}
+}
+
+// toEC2Error converts any error into an EC2 error. Not found errors of the
+// provisioner are reported as InvalidVolume.NotFound.
+func toEC2Error(err error, requestID string) error {
+	e, ok := err.(*ec2Error)
+	if !ok {
+		e = &ec2Error{code: 500, ErrCode: "InternalError", Message: err.Error()}
+		if coded, ok := err.(HTTPCodedError); ok && coded.Code() == 404 {
+			e.code = 400
+			e.ErrCode = "InvalidVolume.NotFound"
+		}
+	}
+
+	e.RequestID = requestID
+	return e
+}
+
+// ec2AdmissionError maps the coded error of a rejected claim to an EC2
+// error. A 403 is mapped to the denied error, while a 422 i.e. an invalid
+// claim is an InvalidParameterValue.
+func ec2AdmissionError(err error, denied *ec2Error) error {
+	coded, ok := err.(HTTPCodedError)
+	if !ok {
+		return err
+	}
+
+	switch coded.Code() {
+	case 403:
+		denied.Message = err.Error()
+		return denied
+	case 422:
+		return &ec2Error{code: 400, ErrCode: "InvalidParameterValue", Message: err.Error()}
+	default:
+		return err
+	}
+}
+
+// randomHex returns a random hex string of the given count of bytes
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
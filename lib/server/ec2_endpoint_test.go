@@ -0,0 +1,313 @@
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/event"
+)
+
+func TestEC2QueryNotFound(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	// Neither a path without route, whatever be the method, nor the root
+	// without Action are served
+	cases := map[string]string{
+		"/oddy":                "GET",
+		"/oddy?Action=Oddy":    "PUT",
+		"/":                    "GET",
+		"/?Version=2016-11-15": "GET",
+	}
+
+	for path, method := range cases {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+
+		s.Server.wrap(RequestCounter, RequestDuration, s.Server.EC2QueryRequest)(resp, req)
+
+		if resp.Code != 404 {
+			t.Fatalf("ERR: %s: http resp code, expected: 404, got: %v", path, resp.Code)
+		}
+	}
+}
+
+func TestEC2QueryErrors(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	cases := map[string]string{
+		"/?Action=RunInstances":                                          "InvalidAction",
+		"/?Action=CreateSnapshot&VolumeId=vol-1":                         "UnsupportedOperation",
+		"/?Action=DescribeSnapshots":                                     "UnsupportedOperation",
+		"/?Action=CreateVolume":                                          "InvalidParameterValue",
+		"/?Action=CreateVolume&Size=1&AvailabilityZone=z":                "InvalidZone.NotFound",
+		"/?Action=DeleteVolume":                                          "MissingParameter",
+		"/?Action=AttachVolume&VolumeId=vol-1":                           "MissingParameter",
+		"/?Action=AttachVolume&VolumeId=vol-1&InstanceId=i-1":            "InvalidInstanceID.NotFound",
+		"/?Action=AttachVolume&VolumeId=vol-1&InstanceId=" + AnyInstance: "MissingParameter",
+		"/?Action=DetachVolume&VolumeId=vol-1":                           "IncorrectState",
+	}
+
+	for path, expected := range cases {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+
+		s.Server.wrap(RequestCounter, RequestDuration, s.Server.EC2QueryRequest)(resp, req)
+
+		if resp.Code != 400 {
+			t.Fatalf("ERR: %s: http resp code, expected: 400, got: %v", path, resp.Code)
+		}
+
+		if ct := resp.Header().Get("Content-Type"); ct != "text/xml;charset=UTF-8" {
+			t.Fatalf("ERR: %s: content type, expected: text/xml, got: %s", path, ct)
+		}
+
+		var out struct {
+			Code      string `xml:"Errors>Error>Code"`
+			RequestID string `xml:"RequestID"`
+		}
+		if err := xml.Unmarshal(resp.Body.Bytes(), &out); err != nil {
+			t.Fatalf("ERR: %s: %v", path, err)
+		}
+
+		if out.Code != expected {
+			t.Fatalf("ERR: %s: expected: %s, got: %s", path, expected, out.Code)
+		}
+
+		if out.RequestID == "" {
+			t.Fatalf("ERR: %s: expected a request id", path)
+		}
+	}
+}
+
+func TestToEC2Volume(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	pv := &v1.PersistentVolume{}
+	pv.Name = "vol-1"
+	pv.Annotations = map[string]string{
+		string(v1.VolumeSizeAPILbl):       "5G",
+		string(v1.ControllerStatusAPILbl): "Running",
+		string(v1.ReplicaStatusAPILbl):    "Running,Pending",
+	}
+
+	vol := s.Server.toEC2Volume(pv)
+
+	if vol.VolumeID != "vol-1" {
+		t.Fatalf("ERR: volume id, expected: vol-1, got: %s", vol.VolumeID)
+	}
+
+	// 5G is rounded up to the next GiB
+	if vol.Size != 5 {
+		t.Fatalf("ERR: size, expected: 5, got: %d", vol.Size)
+	}
+
+	if vol.Status != "available" {
+		t.Fatalf("ERR: status, expected: available, got: %s", vol.Status)
+	}
+
+	if vol.AvailabilityZone != "global-dc1" {
+		t.Fatalf("ERR: zone, expected: global-dc1, got: %s", vol.AvailabilityZone)
+	}
+}
+
+// ec2Call invokes the EC2 Query API with the given params & decodes the
+// response into out
+func ec2Call(t *testing.T, s *TestServer, out interface{}, params ...string) int {
+	form := url.Values{}
+	for i := 0; i+1 < len(params); i += 2 {
+		form.Set(params[i], params[i+1])
+	}
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/?"+form.Encode(), nil)
+
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.EC2QueryRequest)(resp, req)
+
+	if out != nil {
+		if err := xml.Unmarshal(resp.Body.Bytes(), out); err != nil {
+			t.Fatalf("ERR: %s: %v", params[1], err)
+		}
+	}
+	return resp.Code
+}
+
+func TestEC2CreateVolume(t *testing.T) {
+	defer useFakeVolumes()()
+
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	var out struct {
+		VolumeID string `xml:"volumeId"`
+		Size     int64  `xml:"size"`
+	}
+	if code := ec2Call(t, s, &out, "Action", "CreateVolume", "Size", "5"); code != 200 {
+		t.Fatalf("ERR: http resp code, expected: 200, got: %v", code)
+	}
+
+	if out.Size != 5 {
+		t.Fatalf("ERR: size, expected: 5, got: %d", out.Size)
+	}
+
+	// EBS sizes are in GiB
+	pv, ok := fakeVolumes.pvs[out.VolumeID]
+	if !ok {
+		t.Fatalf("ERR: VSM '%s' was not created", out.VolumeID)
+	}
+	if size := pv.Labels[string(v1.PVPStorageSizeLbl)]; size != "5Gi" {
+		t.Fatalf("ERR: storage size, expected: 5Gi, got: %s", size)
+	}
+}
+
+func TestEC2CreateVolumeAdmission(t *testing.T) {
+	defer useFakeVolumes()()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Quotas = []*config.Quota{
+			{Namespace: v1.DefaultOrchestratorNS(), MaxVolumes: 1},
+		}
+		mc.Admissions = []*config.Admission{
+			{
+				Name:    "no-tiny-volumes",
+				Type:    "validating",
+				Names:   []string{"vol-*"},
+				Forbid:  map[string][]string{string(v1.PVPStorageSizeLbl): {"1Gi"}},
+				Message: "volumes of 1Gi are not allowed",
+			},
+		}
+	})
+	defer s.Cleanup()
+
+	var out struct {
+		Code    string `xml:"Errors>Error>Code"`
+		Message string `xml:"Errors>Error>Message"`
+	}
+	if code := ec2Call(t, s, &out, "Action", "CreateVolume", "Size", "1"); code != 403 || out.Code != "UnauthorizedOperation" {
+		t.Fatalf("ERR: expected 403 UnauthorizedOperation, got: %d %s", code, out.Code)
+	}
+	if !strings.Contains(out.Message, "volumes of 1Gi are not allowed") {
+		t.Fatalf("ERR: expected the reason, got: %s", out.Message)
+	}
+
+	if code := ec2Call(t, s, nil, "Action", "CreateVolume", "Size", "2"); code != 200 {
+		t.Fatalf("ERR: http resp code, expected: 200, got: %v", code)
+	}
+
+	// The quota of the default namespace is exhausted
+	if code := ec2Call(t, s, &out, "Action", "CreateVolume", "Size", "2"); code != 400 || out.Code != "VolumeLimitExceeded" {
+		t.Fatalf("ERR: expected 400 VolumeLimitExceeded, got: %d %s", code, out.Code)
+	}
+}
+
+func TestEC2VolumeLifecycleEvents(t *testing.T) {
+	defer useFakeVolumes()()
+
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	index, _ := s.Maya.vsmIndex.current()
+
+	var out struct {
+		VolumeID string `xml:"volumeId"`
+	}
+	if code := ec2Call(t, s, &out, "Action", "CreateVolume", "Size", "1"); code != 200 {
+		t.Fatalf("ERR: http resp code, expected: 200, got: %v", code)
+	}
+	if code := ec2Call(t, s, nil, "Action", "DeleteVolume", "VolumeId", out.VolumeID); code != 200 {
+		t.Fatalf("ERR: http resp code, expected: 200, got: %v", code)
+	}
+	if code := ec2Call(t, s, nil, "Action", "DeleteVolume", "VolumeId", out.VolumeID); code != 400 {
+		t.Fatalf("ERR: http resp code, expected: 400, got: %v", code)
+	}
+
+	// The create & the delete move the index of the blocking queries
+	if current, _ := s.Maya.vsmIndex.current(); current != index+2 {
+		t.Fatalf("ERR: expected index %d, got: %d", index+2, current)
+	}
+
+	var reasons []string
+	for _, e := range s.Maya.events.List(event.Filter{Volume: out.VolumeID}) {
+		reasons = append(reasons, e.Reason)
+	}
+	if !reflect.DeepEqual(reasons, []string{"Provisioned", "Deleted", "DeleteFailed"}) {
+		t.Fatalf("ERR: unexpected events: %v", reasons)
+	}
+}
+
+func TestEC2AttachDetachVolume(t *testing.T) {
+	pv := v1.PersistentVolume{}
+	pv.Name = "vol-1"
+	pv.Annotations = map[string]string{
+		string(v1.VolumeSizeAPILbl):       "1Gi",
+		string(v1.ControllerStatusAPILbl): "Running",
+		string(v1.ReplicaStatusAPILbl):    "Running",
+	}
+	defer useFakeVolumes(pv)()
+
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	describe := func() ec2Volume {
+		var out struct {
+			Items []ec2Volume `xml:"volumeSet>item"`
+		}
+		if code := ec2Call(t, s, &out, "Action", "DescribeVolumes", "VolumeId.1", "vol-1"); code != 200 {
+			t.Fatalf("ERR: describe: http resp code, expected: 200, got: %v", code)
+		}
+		if len(out.Items) != 1 {
+			t.Fatalf("ERR: describe: expected 1 volume, got: %d", len(out.Items))
+		}
+		return out.Items[0]
+	}
+
+	errCode := func(params ...string) string {
+		var out struct {
+			Code string `xml:"Errors>Error>Code"`
+		}
+		if code := ec2Call(t, s, &out, params...); code != 400 {
+			t.Fatalf("ERR: %s: http resp code, expected: 400, got: %v", params[1], code)
+		}
+		return out.Code
+	}
+
+	attach := []string{"Action", "AttachVolume", "VolumeId", "vol-1", "InstanceId", AnyInstance, "Device", "/dev/sdf"}
+	if code := ec2Call(t, s, nil, attach...); code != 200 {
+		t.Fatalf("ERR: attach: http resp code, expected: 200, got: %v", code)
+	}
+
+	vol := describe()
+	if vol.Status != "in-use" {
+		t.Fatalf("ERR: status, expected: in-use, got: %s", vol.Status)
+	}
+	if len(vol.Attachments) != 1 || vol.Attachments[0].Status != "attached" ||
+		vol.Attachments[0].InstanceID != AnyInstance || vol.Attachments[0].Device != "/dev/sdf" {
+		t.Fatalf("ERR: unexpected attachments: %+v", vol.Attachments)
+	}
+
+	// An attached volume can neither be attached again nor be deleted
+	if code := errCode(attach...); code != "VolumeInUse" {
+		t.Fatalf("ERR: attach again: expected: VolumeInUse, got: %s", code)
+	}
+	if code := errCode("Action", "DeleteVolume", "VolumeId", "vol-1"); code != "VolumeInUse" {
+		t.Fatalf("ERR: delete: expected: VolumeInUse, got: %s", code)
+	}
+
+	if code := ec2Call(t, s, nil, "Action", "DetachVolume", "VolumeId", "vol-1"); code != 200 {
+		t.Fatalf("ERR: detach: http resp code, expected: 200, got: %v", code)
+	}
+
+	vol = describe()
+	if vol.Status != "available" || len(vol.Attachments) != 0 {
+		t.Fatalf("ERR: expected an available volume without attachments, got: %s %+v", vol.Status, vol.Attachments)
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/openebs/mayaserver/lib/event"
+	"github.com/openebs/mayaserver/lib/webhook"
+)
+
+// vsmAdded records the outcome of a VSM add as an event & notifies the
+// webhooks of it
+func (ms *MayaApiServer) vsmAdded(vsmName string, err error) {
+	if err != nil {
+		ms.events.Recordf(event.Warning, "ProvisioningFailed", vsmName, "Failed to provision VSM: %v", err)
+		ms.notify(webhook.VolumeCreateFailed, vsmName, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ms.vsmIndex.bump()
+	ms.events.Record(event.Normal, "Provisioned", vsmName, "VSM is provisioned")
+	ms.notify(webhook.VolumeCreated, vsmName, nil)
+}
+
+// vsmDeleted records the outcome of a VSM delete as an event & notifies the
+// webhooks of it
+func (ms *MayaApiServer) vsmDeleted(vsmName string, err error) {
+	if err != nil {
+		ms.events.Recordf(event.Warning, "DeleteFailed", vsmName, "Failed to delete VSM: %v", err)
+		return
+	}
+
+	ms.vsmIndex.bump()
+	ms.events.Record(event.Normal, "Deleted", vsmName, "VSM is deleted")
+	ms.notify(webhook.VolumeDeleted, vsmName, nil)
+}
+
+// EventsRequest is a http handler implementation. It lists the events of
+// maya api server & its VSMs.
+//
+// NOTE:
+//    GET /latest/events?volume=<name>&type=<Normal|Warning>
+func (s *HTTPServer) EventsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	return s.listEvents(req, req.URL.Query().Get("volume"))
+}
+
+// vsmEvents is the http handler that lists the events of a single VSM
+func (s *HTTPServer) vsmEvents(resp http.ResponseWriter, req *http.Request, vsmName string) (interface{}, error) {
+	if vsmName == "" || strings.Contains(vsmName, "/") {
+		return nil, CodedError(400, "VSM name is missing")
+	}
+
+	return s.listEvents(req, vsmName)
+}
+
+// listEvents lists the events of the given VSM, or every event if the VSM
+// is not set, filtered by the type in the query
+func (s *HTTPServer) listEvents(req *http.Request, vsmName string) ([]*event.Event, error) {
+	f := event.Filter{Volume: vsmName}
+
+	if value := req.URL.Query().Get("type"); value != "" {
+		t, err := event.ParseType(value)
+		if err != nil {
+			return nil, CodedError(400, err.Error())
+		}
+		f.Type = t
+	}
+
+	return s.maya.events.List(f), nil
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/event"
+)
+
+func TestEventsRequest(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {})
+	defer s.Cleanup()
+
+	// There is no orchestrator to provision or delete the VSMs
+	for i := 0; i < 2; i++ {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(`{"metadata": {"name": "vol1"}}`))
+		s.Server.wrap(RequestCounter, RequestDuration, s.Server.VSMSpecificRequest)(resp, req)
+	}
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/latest/vsms/vol2", nil)
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.TypedVSMRequest)(resp, req)
+
+	cases := []struct {
+		path     string
+		expected int
+	}{
+		{"/latest/events", 3},
+		{"/latest/events?type=Normal", 1},
+		{"/latest/events?volume=vol2", 1},
+		{"/latest/volumes/vol1/events", 1},
+		{"/v2/volumes/vol1/events?type=Warning", 1},
+		{"/v2/volumes/vol3/events", 0},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", c.path, nil)
+		s.Server.versionRouter(s.Server.mux).ServeHTTP(resp, req)
+
+		if resp.Code != 200 {
+			t.Fatalf("ERR: %s: http resp code, expected: 200, got: %v", c.path, resp.Code)
+		}
+
+		var events []*event.Event
+		if err := json.Unmarshal(resp.Body.Bytes(), &events); err != nil {
+			t.Fatalf("ERR: %s: %v", c.path, err)
+		}
+		if len(events) != c.expected {
+			t.Fatalf("ERR: %s: expected %d events, got: %+v", c.path, c.expected, events)
+		}
+	}
+
+	// The failed adds of vol1 are aggregated
+	events := s.Maya.events.List(event.Filter{Volume: "vol1"})
+	if e := events[0]; e.Type != event.Warning || e.Reason != "ProvisioningFailed" || e.Count != 2 || e.Message == "" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/events?type=Error", nil)
+	_, err := s.Server.EventsRequest(httptest.NewRecorder(), req)
+	if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 400 {
+		t.Fatalf("ERR: expected a 400 coded error, got: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/maya/volumes/provisioner"
+	"github.com/openebs/maya/volumes/provisioner/jiva"
+)
+
+// fakeVolumes are the VSMs served by the fake provisioner. The real jiva
+// provisioner is used while these are not enabled.
+var fakeVolumes = struct {
+	sync.Mutex
+	enabled bool
+	pvs     map[string]v1.PersistentVolume
+}{}
+
+// init registers the fake provisioner as jiva before the server bootstraps
+// its plugins
+func init() {
+	provisioner.RegisterVolumeProvisioner(v1.JivaVolumeProvisioner,
+		func(label, name string) (provisioner.VolumeInterface, error) {
+			fakeVolumes.Lock()
+			defer fakeVolumes.Unlock()
+
+			if !fakeVolumes.enabled {
+				return jiva.NewJivaProvisioner(label, name)
+			}
+			return &fakeProvisioner{label: label, name: name}, nil
+		})
+}
+
+// useFakeVolumes serves the VSMs from memory till the returned func is
+// invoked
+func useFakeVolumes(pvs ...v1.PersistentVolume) func() {
+	fakeVolumes.Lock()
+	defer fakeVolumes.Unlock()
+
+	fakeVolumes.enabled = true
+	fakeVolumes.pvs = map[string]v1.PersistentVolume{}
+	for _, pv := range pvs {
+		fakeVolumes.pvs[pv.Name] = pv
+	}
+
+	return func() {
+		fakeVolumes.Lock()
+		defer fakeVolumes.Unlock()
+
+		fakeVolumes.enabled = false
+		fakeVolumes.pvs = nil
+	}
+}
+
+// fakeProvisioner is a volume provisioner over fakeVolumes
+type fakeProvisioner struct {
+	label string
+	name  string
+	pvc   *v1.PersistentVolumeClaim
+}
+
+func (p *fakeProvisioner) Label() string { return p.label }
+
+func (p *fakeProvisioner) Name() string { return p.name }
+
+func (p *fakeProvisioner) Profile(pvc *v1.PersistentVolumeClaim) (bool, error) {
+	p.pvc = pvc
+	return true, nil
+}
+
+func (p *fakeProvisioner) Remover() (provisioner.Remover, bool, error) { return p, true, nil }
+
+func (p *fakeProvisioner) Reader() (provisioner.Reader, bool) { return p, true }
+
+func (p *fakeProvisioner) Adder() (provisioner.Adder, bool) { return p, true }
+
+func (p *fakeProvisioner) Lister() (provisioner.Lister, bool, error) { return p, true, nil }
+
+func (p *fakeProvisioner) List() (*v1.PersistentVolumeList, error) {
+	fakeVolumes.Lock()
+	defer fakeVolumes.Unlock()
+
+	l := &v1.PersistentVolumeList{}
+	for _, pv := range fakeVolumes.pvs {
+		l.Items = append(l.Items, pv)
+	}
+	return l, nil
+}
+
+func (p *fakeProvisioner) Read(pvc *v1.PersistentVolumeClaim) (*v1.PersistentVolume, error) {
+	fakeVolumes.Lock()
+	defer fakeVolumes.Unlock()
+
+	pv, ok := fakeVolumes.pvs[pvc.Name]
+	if !ok {
+		return nil, nil
+	}
+	return &pv, nil
+}
+
+func (p *fakeProvisioner) Add(pvc *v1.PersistentVolumeClaim) (*v1.PersistentVolume, error) {
+	fakeVolumes.Lock()
+	defer fakeVolumes.Unlock()
+
+	if _, ok := fakeVolumes.pvs[pvc.Name]; ok {
+		return nil, fmt.Errorf("VSM '%s' exists already", pvc.Name)
+	}
+
+	pv := v1.PersistentVolume{}
+	pv.Name = pvc.Name
+	pv.Labels = pvc.Labels
+	pv.Annotations = map[string]string{
+		string(v1.ReplicaCountAPILbl): v1.GetPVPReplicaCount(pvc.Labels),
+	}
+	fakeVolumes.pvs[pv.Name] = pv
+	return &pv, nil
+}
+
+func (p *fakeProvisioner) Remove() (bool, error) {
+	fakeVolumes.Lock()
+	defer fakeVolumes.Unlock()
+
+	if _, ok := fakeVolumes.pvs[p.pvc.Name]; !ok {
+		return false, nil
+	}
+	delete(fakeVolumes.pvs, p.pvc.Name)
+	return true, nil
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+const (
+	// ForwardedFromHeader is set on a request that is forwarded to a peer
+	// with the region of the forwarding maya server. A forwarded request is
+	// never forwarded again.
+	ForwardedFromHeader = "X-Maya-Forwarded-From"
+
+	// AllRegions is the ?region value that selects every region
+	AllRegions = "*"
+)
+
+// hopHeaders are the hop-by-hop headers. These apply to a single connection
+// & are hence not forwarded.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// forwardClient is shared by all the forwarded requests so that the
+// connections to the peers are reused. The timeout of a peer is set per
+// request.
+var forwardClient = &http.Client{}
+
+// forwardRegion returns the region the request has to be forwarded to. The
+// request is served locally if the region is not set, is this region or is
+// all the regions.
+func (s *HTTPServer) forwardRegion(req *http.Request) (string, bool) {
+	var region string
+	s.parseRegion(req, &region)
+
+	if region == s.maya.config.Region || region == AllRegions {
+		return "", false
+	}
+
+	return region, true
+}
+
+// forward proxies the request to a peer of the given region & copies the
+// peer's response. The peer's addresses are tried in order till one of them
+// is reachable. The status code of the response is returned.
+func (s *HTTPServer) forward(resp http.ResponseWriter, req *http.Request, region string) (int, error) {
+	if from := req.Header.Get(ForwardedFromHeader); from != "" {
+		return 0, CodedError(502, fmt.Sprintf("Request for region '%s' was already forwarded by region '%s'", region, from))
+	}
+
+	peer := s.maya.config.Peer(region)
+	if peer == nil {
+		return 0, CodedError(502, fmt.Sprintf("No peer is configured for region '%s'", region))
+	}
+
+	// The body is buffered since it may be sent to more than one address
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return 0, bodyError(err)
+		}
+	}
+
+	out, err := s.forwardToPeer(peer, req, body)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Body.Close()
+
+	// The peer's headers replace the ones that are already set e.g. the
+	// configured response headers
+	copyHeaders(resp.Header(), out.Header)
+	resp.WriteHeader(out.StatusCode)
+	io.Copy(resp, out.Body)
+
+	return out.StatusCode, nil
+}
+
+// forwardToPeer tries the peer's addresses in order & returns the response
+// of the first address that is reachable
+func (s *HTTPServer) forwardToPeer(peer *config.Peer, req *http.Request, body []byte) (*http.Response, error) {
+	for _, addr := range peer.Addresses {
+		out, err := s.forwardTo(peer, addr, req, body)
+		if err != nil {
+			s.logger.Printf("[WARN] http: Forwarding %v %v to region '%s' at '%s' failed: %v", req.Method, req.URL, peer.Region, addr, err)
+			continue
+		}
+
+		return out, nil
+	}
+
+	return nil, CodedError(502, fmt.Sprintf("No peer is available in region '%s'", peer.Region))
+}
+
+// forwardTo sends the request to a single address of the peer. All the
+// headers including the auth headers are preserved.
+func (s *HTTPServer) forwardTo(peer *config.Peer, addr string, req *http.Request, body []byte) (*http.Response, error) {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	out, err := http.NewRequest(req.Method, strings.TrimSuffix(addr, "/")+requestURI(req), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	copyHeaders(out.Header, req.Header)
+	out.Header.Set(ForwardedFromHeader, s.maya.config.Region)
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		out.Header.Add("X-Forwarded-For", host)
+	}
+
+	timeout := peer.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultPeerTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	res, err := forwardClient.Do(out.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The timeout covers reading the body as well
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelBody cancels the context of the request once its response body is
+// closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// copyHeaders sets the headers of src on dst leaving out the hop-by-hop
+// headers as well as the ones listed in the Connection header
+func copyHeaders(dst, src http.Header) {
+	skip := map[string]bool{}
+	for _, h := range hopHeaders {
+		skip[h] = true
+	}
+	for _, v := range src["Connection"] {
+		for _, h := range strings.Split(v, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				skip[http.CanonicalHeaderKey(h)] = true
+			}
+		}
+	}
+
+	for k, vv := range src {
+		if skip[http.CanonicalHeaderKey(k)] {
+			continue
+		}
+		dst[k] = append([]string(nil), vv...)
+	}
+}
+
+// requestURI returns the URI of the request as it was received. The path of
+// a versioned request is rewritten by the version router & hence the
+// original request URI is preferred.
+func requestURI(req *http.Request) string {
+	if req.RequestURI != "" {
+		if u, err := url.ParseRequestURI(req.RequestURI); err == nil {
+			return u.RequestURI()
+		}
+	}
+	return req.URL.RequestURI()
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestForwardRegion(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	cases := map[string]bool{
+		"/latest/vsms/":                false,
+		"/latest/vsms/?region=global":  false,
+		"/latest/vsms/?region=*":       false,
+		"/latest/vsms/?region=west":    true,
+		"/latest/vsms/vol1?region=dc2": true,
+	}
+
+	for path, expected := range cases {
+		req, _ := http.NewRequest("GET", path, nil)
+		if _, ok := s.Server.forwardRegion(req); ok != expected {
+			t.Fatalf("ERR: %s: expected: %v, got: %v", path, expected, ok)
+		}
+	}
+}
+
+func TestForwardToPeer(t *testing.T) {
+	peer := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Region = "west"
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "s3cr3t"}
+	})
+	defer peer.Cleanup()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Peers = []*config.Peer{
+			{
+				Region: "west",
+				// The first address is not reachable
+				Addresses: []string{"127.0.0.1:1", peer.Server.addr},
+			},
+		}
+	})
+	defer s.Cleanup()
+
+	// The monitor of the peer needs the management token
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/latest/agent/monitor?region=west", nil)
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.AgentSpecificRequest)(resp, req)
+
+	if resp.Code != 403 {
+		t.Fatalf("ERR: http resp code, expected: 403, got: %v", resp.Code)
+	}
+
+	// The auth header is preserved
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latest/agent/self?region=west", nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.AgentSpecificRequest)(resp, req)
+
+	if resp.Code != 200 {
+		t.Fatalf("ERR: http resp code, expected: 200, got: %v: %s", resp.Code, resp.Body.String())
+	}
+
+	if !strings.Contains(resp.Body.String(), `"region":"west"`) {
+		t.Fatalf("ERR: expected the response of the peer, got: %s", resp.Body.String())
+	}
+}
+
+func TestForwardNoPeer(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Peers = []*config.Peer{
+			{Region: "west", Addresses: []string{"127.0.0.1:1"}},
+		}
+	})
+	defer s.Cleanup()
+
+	cases := []struct {
+		path      string
+		forwarded bool
+	}{
+		// not configured
+		{"/latest/agent/self?region=east", false},
+		// not reachable
+		{"/latest/agent/self?region=west", false},
+		// already forwarded
+		{"/latest/agent/self?region=west", true},
+	}
+
+	for _, c := range cases {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", c.path, nil)
+		if c.forwarded {
+			req.Header.Set(ForwardedFromHeader, "east")
+		}
+
+		s.Server.wrap(RequestCounter, RequestDuration, s.Server.AgentSpecificRequest)(resp, req)
+
+		if resp.Code != 502 {
+			t.Fatalf("ERR: %s: http resp code, expected: 502, got: %v", c.path, resp.Code)
+		}
+	}
+}
+
+func TestCopyHeaders(t *testing.T) {
+	src := http.Header{}
+	src.Set("Content-Type", "application/json")
+	src.Set("Connection", "keep-alive, X-Hop")
+	src.Set("X-Hop", "1")
+	src.Set("Transfer-Encoding", "chunked")
+	src.Set("Proxy-Authorization", "Basic oddy")
+	src.Add("X-Custom", "peer")
+
+	dst := http.Header{}
+	dst.Set("X-Custom", "local")
+
+	copyHeaders(dst, src)
+
+	// The headers of src replace the ones of dst
+	if vv := dst["X-Custom"]; len(vv) != 1 || vv[0] != "peer" {
+		t.Fatalf("ERR: expected X-Custom: [peer], got: %v", vv)
+	}
+
+	if ct := dst.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("ERR: expected Content-Type: application/json, got: %s", ct)
+	}
+
+	for _, h := range []string{"Connection", "X-Hop", "Transfer-Encoding", "Proxy-Authorization"} {
+		if _, ok := dst[h]; ok {
+			t.Fatalf("ERR: hop-by-hop header %s must not be copied", h)
+		}
+	}
+}
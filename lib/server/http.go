@@ -18,6 +18,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -92,6 +93,234 @@ var (
 		},
 		[]string{"code", "method"},
 	)
+	// latestOpenEBSAPITokenRequestDuration Collects the response time since
+	// a request has been made on /latest/api/token
+	latestOpenEBSAPITokenRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_api_token_request_duration_seconds",
+			Help:    "Request response time of the /latest/api/token.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSAPITokenRequestCounter Count the no of request Since a
+	// request has been made on /latest/api/token
+	latestOpenEBSAPITokenRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_api_token_requests_total",
+			Help: "Total number of /latest/api/token requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// openebsEC2QueryRequestDuration Collects the response time since a
+	// request has been made on the EC2 Query API
+	openebsEC2QueryRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "openebs_ec2_query_request_duration_seconds",
+			Help:    "Request response time of the EC2 Query API.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// openebsEC2QueryRequestCounter Count the no of request Since a
+	// request has been made on the EC2 Query API
+	openebsEC2QueryRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "openebs_ec2_query_requests_total",
+			Help: "Total number of EC2 Query API requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSStatusRequestDuration Collects the response time since a
+	// request has been made on /latest/status
+	latestOpenEBSStatusRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_status_request_duration_seconds",
+			Help:    "Request response time of the /latest/status.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSStatusRequestCounter Count the no of request Since a
+	// request has been made on /latest/status
+	latestOpenEBSStatusRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_status_requests_total",
+			Help: "Total number of /latest/status requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSAgentRequestDuration Collects the response time since a
+	// request has been made on /latest/agent
+	latestOpenEBSAgentRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_agent_request_duration_seconds",
+			Help:    "Request response time of the /latest/agent.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSAgentRequestCounter Count the no of request Since a
+	// request has been made on /latest/agent
+	latestOpenEBSAgentRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_agent_requests_total",
+			Help: "Total number of /latest/agent requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSPluginsRequestDuration Collects the response time since
+	// a request has been made on /latest/plugins
+	latestOpenEBSPluginsRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_plugins_request_duration_seconds",
+			Help:    "Request response time of the /latest/plugins.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSPluginsRequestCounter Count the no of request Since a
+	// request has been made on /latest/plugins
+	latestOpenEBSPluginsRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_plugins_requests_total",
+			Help: "Total number of /latest/plugins requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSAuditRequestDuration Collects the response time since a
+	// request has been made on /latest/audit
+	latestOpenEBSAuditRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_audit_request_duration_seconds",
+			Help:    "Request response time of the /latest/audit.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSAuditRequestCounter Count the no of request Since a
+	// request has been made on /latest/audit
+	latestOpenEBSAuditRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_audit_requests_total",
+			Help: "Total number of /latest/audit requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSWebhooksRequestDuration Collects the response time since a
+	// request has been made on /latest/webhooks
+	latestOpenEBSWebhooksRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_webhooks_request_duration_seconds",
+			Help:    "Request response time of the /latest/webhooks.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSWebhooksRequestCounter Count the no of request Since a
+	// request has been made on /latest/webhooks
+	latestOpenEBSWebhooksRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_webhooks_requests_total",
+			Help: "Total number of /latest/webhooks requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSEventsRequestDuration Collects the response time since a
+	// request has been made on /latest/events
+	latestOpenEBSEventsRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_events_request_duration_seconds",
+			Help:    "Request response time of the /latest/events.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSEventsRequestCounter Count the no of request Since a
+	// request has been made on /latest/events
+	latestOpenEBSEventsRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_events_requests_total",
+			Help: "Total number of /latest/events requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSQuotasRequestDuration Collects the response time since a
+	// request has been made on /latest/quotas
+	latestOpenEBSQuotasRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_quotas_request_duration_seconds",
+			Help:    "Request response time of the /latest/quotas.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSQuotasRequestCounter Count the no of request Since a
+	// request has been made on /latest/quotas
+	latestOpenEBSQuotasRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_quotas_requests_total",
+			Help: "Total number of /latest/quotas requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSStorageClassesRequestDuration Collects the response time
+	// since a request has been made on /latest/storageclasses
+	latestOpenEBSStorageClassesRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_storageclasses_request_duration_seconds",
+			Help:    "Request response time of the /latest/storageclasses.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSStorageClassesRequestCounter Count the no of request Since
+	// a request has been made on /latest/storageclasses
+	latestOpenEBSStorageClassesRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_storageclasses_requests_total",
+			Help: "Total number of /latest/storageclasses requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// v1OpenEBSVolumeRequestDuration Collects the response time since a
+	// request has been made on /v1/volumes
+	v1OpenEBSVolumeRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "v1_openebs_volume_request_duration_seconds",
+			Help:    "Request response time of the /v1/volumes.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// v1OpenEBSVolumeRequestCounter Count the no of request Since a
+	// request has been made on /v1/volumes
+	v1OpenEBSVolumeRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "v1_openebs_volume_requests_total",
+			Help: "Total number of /v1/volumes requests.",
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSVSMRequestDuration Collects the response time since a
+	// request has been made on /latest/vsms
+	latestOpenEBSVSMRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "latest_openebs_vsm_request_duration_seconds",
+			Help:    "Request response time of the /latest/vsms.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"code", "method"},
+	)
+	// latestOpenEBSVSMRequestCounter Count the no of request Since a
+	// request has been made on /latest/vsms
+	latestOpenEBSVSMRequestCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "latest_openebs_vsm_requests_total",
+			Help: "Total number of /latest/vsms requests.",
+		},
+		[]string{"code", "method"},
+	)
 )
 
 // HTTPServer is used to wrap maya api server and expose it over an HTTP interface
@@ -105,6 +334,22 @@ type HTTPServer struct {
 	listener net.Listener
 	logger   *log.Logger
 	addr     string
+
+	// metaTokens signs & validates the session tokens of the metadata endpoint
+	metaTokens *metaTokenSigner
+
+	// ec2Attachments are the attachments of the volumes done via the EC2
+	// Query API
+	ec2Attachments *ec2AttachmentStore
+
+	// adminMux & adminListener serve the debug endpoints when an admin port
+	// is configured
+	adminMux      *http.ServeMux
+	adminListener net.Listener
+
+	// vsmLock serializes the VSM mutations so that neither a precondition
+	// nor a quota is invalidated by a concurrent mutation
+	vsmLock sync.Mutex
 }
 
 // init registers Prometheus metrics.It's good to register these varibles here
@@ -116,10 +361,47 @@ func init() {
 	prometheus.MustRegister(latestOpenEBSVolumeRequestCounter)
 	prometheus.MustRegister(latestOpenEBSMetaDataRequestDuration)
 	prometheus.MustRegister(latestOpenEBSMetaDataRequestCounter)
+	prometheus.MustRegister(v1OpenEBSVolumeRequestDuration)
+	prometheus.MustRegister(v1OpenEBSVolumeRequestCounter)
+	prometheus.MustRegister(latestOpenEBSVSMRequestDuration)
+	prometheus.MustRegister(latestOpenEBSVSMRequestCounter)
+	prometheus.MustRegister(latestOpenEBSAPITokenRequestDuration)
+	prometheus.MustRegister(latestOpenEBSAPITokenRequestCounter)
+	prometheus.MustRegister(openebsEC2QueryRequestDuration)
+	prometheus.MustRegister(openebsEC2QueryRequestCounter)
+	prometheus.MustRegister(latestOpenEBSStatusRequestDuration)
+	prometheus.MustRegister(latestOpenEBSStatusRequestCounter)
+	prometheus.MustRegister(latestOpenEBSAgentRequestDuration)
+	prometheus.MustRegister(latestOpenEBSAgentRequestCounter)
+	prometheus.MustRegister(latestOpenEBSPluginsRequestDuration)
+	prometheus.MustRegister(latestOpenEBSPluginsRequestCounter)
+	prometheus.MustRegister(latestOpenEBSAuditRequestDuration)
+	prometheus.MustRegister(latestOpenEBSAuditRequestCounter)
+	prometheus.MustRegister(latestOpenEBSWebhooksRequestDuration)
+	prometheus.MustRegister(latestOpenEBSWebhooksRequestCounter)
+	prometheus.MustRegister(latestOpenEBSEventsRequestDuration)
+	prometheus.MustRegister(latestOpenEBSEventsRequestCounter)
+	prometheus.MustRegister(latestOpenEBSQuotasRequestDuration)
+	prometheus.MustRegister(latestOpenEBSQuotasRequestCounter)
+	prometheus.MustRegister(latestOpenEBSStorageClassesRequestDuration)
+	prometheus.MustRegister(latestOpenEBSStorageClassesRequestCounter)
 }
 
 // NewHTTPServer starts new HTTP server over Maya server
 func NewHTTPServer(maya *MayaApiServer, config *config.MayaConfig, logOutput io.Writer) (*HTTPServer, error) {
+	if err := validAPIVersion(config.LatestAPIVersion); err != nil {
+		return nil, err
+	}
+
+	if err := validDebugConfig(config); err != nil {
+		return nil, err
+	}
+
+	metaTokens, err := newMetaTokenSigner()
+	if err != nil {
+		return nil, err
+	}
+
 	// Start the listener
 	lnAddr, err := net.ResolveTCPAddr("tcp", config.NormalizedAddrs.HTTP)
 	if err != nil {
@@ -157,7 +439,28 @@ func NewHTTPServer(maya *MayaApiServer, config *config.MayaConfig, logOutput io.
 		listener: ln,
 		logger:   maya.logger,
 		addr:     ln.Addr().String(),
+
+		metaTokens:     metaTokens,
+		ec2Attachments: newEC2AttachmentStore(),
+	}
+
+	// The debug endpoints are served on a separate listener if an admin port
+	// is configured
+	if config.EnableDebug && config.NormalizedAddrs.Admin != "" {
+		adminAddr, err := net.ResolveTCPAddr("tcp", config.NormalizedAddrs.Admin)
+		if err != nil {
+			ln.Close()
+			return nil, err
+		}
+		adminLn, err := config.Listener("tcp", adminAddr.IP.String(), adminAddr.Port)
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to start admin listener: %v", err)
+		}
+		srv.adminMux = http.NewServeMux()
+		srv.adminListener = adminLn
 	}
+
 	srv.registerHandlers(config.ServiceProvider, config.EnableDebug)
 
 	// Start the server
@@ -167,7 +470,11 @@ func NewHTTPServer(maya *MayaApiServer, config *config.MayaConfig, logOutput io.
 	// we are not using GzipHandler.This issue may be related to GzipHandler
 	// GzipHandler may be used later.
 	//	go http.Serve(ln, gziphandler.GzipHandler(mux))
-	go http.Serve(ln, mux)
+	go http.Serve(ln, srv.versionRouter(mux))
+
+	if srv.adminListener != nil {
+		go http.Serve(srv.adminListener, srv.adminMux)
+	}
 
 	return srv, nil
 }
@@ -194,6 +501,9 @@ func (s *HTTPServer) Shutdown() {
 	if s != nil {
 		s.logger.Printf("[DEBUG] http: Shutting down http server")
 		s.listener.Close()
+		if s.adminListener != nil {
+			s.adminListener.Close()
+		}
 	}
 }
 
@@ -210,14 +520,87 @@ func (s *HTTPServer) registerHandlers(serviceProvider string, enableDebug bool)
 	s.mux.HandleFunc("/latest/meta-data/", s.wrap(latestOpenEBSMetaDataRequestCounter,
 		latestOpenEBSMetaDataRequestDuration, s.MetaSpecificRequest))
 
+	// Session tokens for the metadata endpoint are issued here
+	s.mux.HandleFunc("/latest/api/token", s.wrap(latestOpenEBSAPITokenRequestCounter,
+		latestOpenEBSAPITokenRequestDuration, s.MetaTokenRequest))
+
 	// Request w.r.t to a single VSM entity is handled here
 	s.mux.HandleFunc("/latest/volumes/", s.wrap(latestOpenEBSVolumeRequestCounter,
 		latestOpenEBSVolumeRequestDuration, s.VSMSpecificRequest))
+
+	// Request w.r.t a single VSM entity of the frozen v1 API is handled here
+	s.mux.HandleFunc("/v1/volumes/", s.wrap(v1OpenEBSVolumeRequestCounter,
+		v1OpenEBSVolumeRequestDuration, s.V1VSMSpecificRequest))
+
+	// Request w.r.t typed VSM resources is handled here
+	s.mux.HandleFunc("/latest/vsms/", s.wrap(latestOpenEBSVSMRequestCounter,
+		latestOpenEBSVSMRequestDuration, s.TypedVSMRequest))
+
+	// Liveness & readiness probes are handled here
+	s.mux.HandleFunc("/latest/status/", s.wrap(latestOpenEBSStatusRequestCounter,
+		latestOpenEBSStatusRequestDuration, s.StatusSpecificRequest))
+
+	// Request w.r.t this maya api server is handled here
+	s.mux.HandleFunc("/latest/agent/", s.wrap(latestOpenEBSAgentRequestCounter,
+		latestOpenEBSAgentRequestDuration, s.AgentSpecificRequest))
+
+	// Registered plugins are listed here
+	s.mux.HandleFunc("/latest/plugins", s.wrap(latestOpenEBSPluginsRequestCounter,
+		latestOpenEBSPluginsRequestDuration, s.PluginsRequest))
+
+	// The audit log of the mutating requests is queried here
+	s.mux.HandleFunc("/latest/audit", s.wrap(latestOpenEBSAuditRequestCounter,
+		latestOpenEBSAuditRequestDuration, s.AuditRequest))
+
+	// The delivery status of the webhooks is served here
+	s.mux.HandleFunc("/latest/webhooks", s.wrap(latestOpenEBSWebhooksRequestCounter,
+		latestOpenEBSWebhooksRequestDuration, s.WebhooksRequest))
+	s.mux.HandleFunc("/latest/webhooks/", s.wrap(latestOpenEBSWebhooksRequestCounter,
+		latestOpenEBSWebhooksRequestDuration, s.WebhooksRequest))
+
+	// The events of maya api server & its VSMs are listed here
+	s.mux.HandleFunc("/latest/events", s.wrap(latestOpenEBSEventsRequestCounter,
+		latestOpenEBSEventsRequestDuration, s.EventsRequest))
+
+	// The quotas of the namespaces are served & set here
+	s.mux.HandleFunc("/latest/quotas", s.wrap(latestOpenEBSQuotasRequestCounter,
+		latestOpenEBSQuotasRequestDuration, s.QuotasRequest))
+	s.mux.HandleFunc("/latest/quotas/", s.wrap(latestOpenEBSQuotasRequestCounter,
+		latestOpenEBSQuotasRequestDuration, s.QuotasRequest))
+
+	// The configured storage classes are listed here
+	s.mux.HandleFunc("/latest/storageclasses", s.wrap(latestOpenEBSStorageClassesRequestCounter,
+		latestOpenEBSStorageClassesRequestDuration, s.StorageClassesRequest))
+	s.mux.HandleFunc("/latest/storageclasses/", s.wrap(latestOpenEBSStorageClassesRequestCounter,
+		latestOpenEBSStorageClassesRequestDuration, s.StorageClassesRequest))
+
+	// EBS volume calls of the EC2 Query API are handled here. The pattern
+	// matches every path that is not matched by the other routes, but only
+	// the root is served & anything else is not found.
+	s.mux.HandleFunc("/", s.wrap(openebsEC2QueryRequestCounter,
+		openebsEC2QueryRequestDuration, s.EC2QueryRequest))
+
 	// request for metrics is handled here. It displays metrics related to
 	// garbage collection, process, cpu...etc, and the custom metrics created.
 	s.mux.Handle("/metrics", promhttp.Handler())
+
+	// Debug endpoints are served only if enabled
+	if enableDebug {
+		if s.adminMux != nil {
+			s.registerDebugHandlers(s.adminMux)
+		} else {
+			s.registerDebugHandlers(s.mux)
+		}
+	}
 }
 
+// textResponse is a handler response that is written as plain text instead
+// of being encoded as JSON
+type textResponse string
+
+// xmlResponse is a handler response that is already encoded as XML
+type xmlResponse string
+
 // HTTPCodedError is used to provide the HTTP error code
 type HTTPCodedError interface {
 	error
@@ -271,6 +654,28 @@ func (s *HTTPServer) wrap(RequestCounter *prometheus.CounterVec, RequestDuration
 		}()
 
 		s.logger.Printf("[DEBUG] http: Request %v (%v)", reqURL, req.Method)
+
+		s.limitBody(resp, req)
+
+		// Mutating requests are audited once they are served
+		if aw := s.startAudit(resp, req); aw != nil {
+			defer aw.finish()
+			resp = aw
+		}
+
+		// Requests for another region are forwarded to a peer of that region
+		if region, ok := s.forwardRegion(req); ok {
+			var err error
+			if code, err = s.forward(resp, req, region); err == nil {
+				return
+			}
+			s.logger.Printf("[ERR] http: Request %v %v, error: %v", req.Method, reqURL, err)
+			code = err.(HTTPCodedError).Code()
+			resp.WriteHeader(code)
+			resp.Write([]byte(err.Error()))
+			return
+		}
+
 		// Original handler is invoked
 		obj, err := handler(resp, req)
 
@@ -295,6 +700,20 @@ func (s *HTTPServer) wrap(RequestCounter *prometheus.CounterVec, RequestDuration
 			}
 		}
 
+		// Plain text responses are written as is
+		if text, ok := obj.(textResponse); ok {
+			resp.Header().Set("Content-Type", "text/plain")
+			resp.Write([]byte(text))
+			return
+		}
+
+		// XML responses are written as is
+		if x, ok := obj.(xmlResponse); ok {
+			resp.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+			resp.Write([]byte(x))
+			return
+		}
+
 		// Transform the response structure to its JSON equivalent
 		if obj != nil {
 			var buf bytes.Buffer
@@ -332,37 +751,124 @@ func getContentType(req *http.Request) (string, error) {
 }
 
 // Decode the request body to appropriate structure based on content
-// type
-func decodeBody(req *http.Request, out interface{}) error {
+// type. Unknown fields are rejected if strict decoding is enabled.
+//
+// NOTE:
+//    A JSON body is assumed if the content type is not set. Any other
+// content type than JSON or YAML is rejected with 415.
+func (s *HTTPServer) decodeBody(req *http.Request, out interface{}) error {
 
 	cType, err := getContentType(req)
 	if err != nil {
-		return err
+		return CodedError(400, err.Error())
 	}
 
-	if strings.Contains(cType, "yaml") {
-		return decodeYamlBody(req, out)
+	strict := s.maya.config.StrictDecoding
+
+	switch {
+	case strings.Contains(cType, "yaml"):
+		err = decodeYamlBody(req, out, strict)
+	case cType == "" || strings.Contains(cType, "json"):
+		err = decodeJsonBody(req, out, strict)
+	default:
+		return CodedError(415, fmt.Sprintf("Unsupported content type '%s', expected JSON or YAML", cType))
 	}
 
-	// default is assumed to be json content
-	return decodeJsonBody(req, out)
+	if err != nil {
+		return bodyError(err)
+	}
+	return nil
 }
 
 // decodeJsonBody is used to decode a JSON request body
-func decodeJsonBody(req *http.Request, out interface{}) error {
-	dec := json.NewDecoder(req.Body)
-	return dec.Decode(&out)
+func decodeJsonBody(req *http.Request, out interface{}, strict bool) error {
+	if !strict {
+		return json.NewDecoder(req.Body).Decode(&out)
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, &out); err != nil {
+		return err
+	}
+
+	return checkUnknownJsonFields(b, out)
 }
 
 // decodeYamlBody is used to decode a YAML request body
-func decodeYamlBody(req *http.Request, out interface{}) error {
+func decodeYamlBody(req *http.Request, out interface{}, strict bool) error {
 	// Get []bytes from io.Reader
 	b, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		return err
 	}
 
-	return yaml.Unmarshal(b, &out)
+	if err := yaml.Unmarshal(b, &out); err != nil {
+		return err
+	}
+
+	if !strict {
+		return nil
+	}
+
+	return CheckUnknownYamlFields(b, out)
+}
+
+// bodyError converts the error of reading or decoding a request body into
+// a http error. The error of a body that exceeds the limit is a coded error
+// already.
+func bodyError(err error) error {
+	if _, ok := err.(HTTPCodedError); ok {
+		return err
+	}
+	return CodedError(400, err.Error())
+}
+
+// limitBody limits the size of the request body to the configured maximum
+func (s *HTTPServer) limitBody(resp http.ResponseWriter, req *http.Request) {
+	limit := s.maya.config.MaxRequestBodySize
+	if limit <= 0 {
+		limit = config.DefaultMaxRequestBodySize
+	}
+
+	if req.Body != nil {
+		req.Body = &limitedBody{ReadCloser: req.Body, limit: limit, remaining: limit}
+	}
+}
+
+// limitedBody is a request body that fails with 413 once more than limit
+// bytes are read from it
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// The limit is reached. The body is too large only if there is more to
+	// read.
+	if b.remaining <= 0 {
+		var one [1]byte
+		n, err := b.ReadCloser.Read(one[:])
+		if n > 0 {
+			return 0, CodedError(413, fmt.Sprintf("Request body exceeds the limit of %d bytes", b.limit))
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
 }
 
 // setIndex is used to set the index response header
@@ -389,31 +895,6 @@ func setHeaders(resp http.ResponseWriter, headers map[string]string) {
 	}
 }
 
-// parseWait is used to parse the ?wait and ?index query params
-// Returns true on error
-//func parseWait(resp http.ResponseWriter, req *http.Request, qo *structs.QueryOptions) bool {
-//	query := req.URL.Query()
-//	if wait := query.Get("wait"); wait != "" {
-//		dur, err := time.ParseDuration(wait)
-//		if err != nil {
-//			resp.WriteHeader(400)
-//			resp.Write([]byte("Invalid wait time"))
-//			return true
-//		}
-//		qo.MaxQueryTime = dur
-//	}
-//	if idx := query.Get("index"); idx != "" {
-//		index, err := strconv.ParseUint(idx, 10, 64)
-//		if err != nil {
-//			resp.WriteHeader(400)
-//			resp.Write([]byte("Invalid index"))
-//			return true
-//		}
-//		qo.MinQueryIndex = index
-//	}
-//	return false
-//}
-
 // parseConsistency is used to parse the ?stale query params.
 //func parseConsistency(req *http.Request, qo *structs.QueryOptions) {
 //	query := req.URL.Query()
@@ -11,7 +11,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strconv"
 	"testing"
 	"time"
@@ -39,19 +38,6 @@ var (
 	)
 )
 
-type TestServer struct {
-	T      testing.TB
-	Dir    string
-	Maya   *MayaApiServer
-	Server *HTTPServer
-}
-
-func (s *TestServer) Cleanup() {
-	s.Server.Shutdown()
-	s.Maya.Shutdown()
-	os.RemoveAll(s.Dir)
-}
-
 // makeHTTPTestServer returns a test server with full logging.
 func makeHTTPTestServer(t testing.TB, fnmc func(mc *config.MayaConfig)) *TestServer {
 	return makeHTTPTestServerWithWriter(t, nil, fnmc)
@@ -63,26 +49,6 @@ func makeHTTPTestServerNoLogs(t testing.TB, fnmc func(mc *config.MayaConfig)) *T
 	return makeHTTPTestServerWithWriter(t, ioutil.Discard, fnmc)
 }
 
-// makeHTTPTestServerWithWriter returns a test server whose logs will be written to
-// the passed writer. If the writer is nil, the logs are written to stderr.
-func makeHTTPTestServerWithWriter(t testing.TB, w io.Writer, fnmc func(mc *config.MayaConfig)) *TestServer {
-	dir, maya := makeMayaServer(t, fnmc)
-	if w == nil {
-		w = maya.logOutput
-	}
-	srv, err := NewHTTPServer(maya, maya.config, w)
-	if err != nil {
-		t.Fatalf("err: %v", err)
-	}
-	s := &TestServer{
-		T:      t,
-		Dir:    dir,
-		Maya:   maya,
-		Server: srv,
-	}
-	return s
-}
-
 func BenchmarkHTTPRequests(b *testing.B) {
 	s := makeHTTPTestServerNoLogs(b, func(mc *config.MayaConfig) {
 
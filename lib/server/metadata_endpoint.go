@@ -1,7 +1,11 @@
 package server
 
 import (
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"sort"
 	"strings"
 )
 
@@ -10,10 +14,22 @@ const (
 	// any type of compute instance
 	AnyInstance = "any-compute"
 
-	// TODO We shall see how to construct an Availability Zone
+	// AnyZone is the availability zone that is served when neither the
+	// zone nor the region & datacenter are configured
 	AnyZone = "any-zone"
 )
 
+// metaTree is a node of the metadata tree. A node is either a directory
+// i.e. a metaTree or a leaf i.e. a string value.
+type metaTree map[string]interface{}
+
+// MetaSpecificRequest is a http handler implementation. It serves the
+// metadata tree in the EC2 instance metadata style.
+//
+// NOTE:
+//    A leaf is served as its plain text value while a directory is served as
+// the newline separated list of its children. Child directories have a
+// trailing '/'.
 func (s *HTTPServer) MetaSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 
 	path := strings.TrimPrefix(req.URL.Path, "/latest/meta-data")
@@ -23,36 +39,126 @@ func (s *HTTPServer) MetaSpecificRequest(resp http.ResponseWriter, req *http.Req
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
-	// We do an exact suffix comparision
-	switch {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
 
-	case strings.Compare(path, "/instance-id") == 0:
-		return s.metaInstanceID(resp, req)
+	if err := s.checkMetaToken(req); err != nil {
+		return nil, err
+	}
 
-	case strings.Compare(path, "/placement/availability-zone") == 0:
-		return s.metaAvailabilityZone(resp, req)
+	var node interface{} = s.metaTree()
+	for _, key := range strings.Split(strings.Trim(path, "/"), "/") {
+		if key == "" {
+			continue
+		}
+
+		// A leaf has no children
+		dir, ok := node.(metaTree)
+		if !ok {
+			return nil, CodedError(404, fmt.Sprintf("Metadata '%s' not found", path))
+		}
+
+		if node, ok = dir[key]; !ok {
+			return nil, CodedError(404, fmt.Sprintf("Metadata '%s' not found", path))
+		}
+	}
 
+	switch n := node.(type) {
+	case metaTree:
+		return textResponse(n.list()), nil
 	default:
-		return nil, CodedError(405, ErrInvalidMethod)
+		return textResponse(n.(string)), nil
+	}
+}
+
+// metaTree builds the metadata tree from the config of maya server
+func (s *HTTPServer) metaTree() metaTree {
+	return metaTree{
+		"instance-id":    s.metaInstanceID(),
+		"hostname":       s.metaHostname(),
+		"local-hostname": s.metaHostname(),
+		"local-ipv4":     s.metaLocalIPv4(),
+		"placement": metaTree{
+			"availability-zone": s.metaAvailabilityZone(),
+			"region":            s.maya.config.Region,
+		},
+	}
+}
+
+// list returns the newline separated & sorted children of the directory
+func (t metaTree) list() string {
+	keys := make([]string, 0, len(t))
+	for k, v := range t {
+		if _, ok := v.(metaTree); ok {
+			k = k + "/"
+		}
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, "\n")
 }
 
 // EBS demands a particular instance id to be returned during
 // aws session creation.
-func (s *HTTPServer) metaInstanceID(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+func (s *HTTPServer) metaInstanceID() string {
+	if md := s.maya.config.Metadata; md != nil && md.InstanceID != "" {
+		return md.InstanceID
+	}
 
-	if req.Method != "GET" {
-		return nil, CodedError(405, ErrInvalidMethod)
+	return AnyInstance
+}
+
+// metaAvailabilityZone derives the zone as <region>-<datacenter> if it
+// is not configured
+func (s *HTTPServer) metaAvailabilityZone() string {
+	if md := s.maya.config.Metadata; md != nil && md.AvailabilityZone != "" {
+		return md.AvailabilityZone
+	}
+
+	var parts []string
+	for _, p := range []string{s.maya.config.Region, s.maya.config.Datacenter} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return AnyZone
 	}
 
-	return AnyInstance, nil
+	return strings.Join(parts, "-")
 }
 
-func (s *HTTPServer) metaAvailabilityZone(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+// metaLocalIPv4 defaults to the host of the advertised HTTP address
+func (s *HTTPServer) metaLocalIPv4() string {
+	if md := s.maya.config.Metadata; md != nil && md.LocalIPv4 != "" {
+		return md.LocalIPv4
+	}
 
-	if req.Method != "GET" {
-		return nil, CodedError(405, ErrInvalidMethod)
+	if s.maya.config.AdvertiseAddrs == nil {
+		return ""
+	}
+
+	addr := s.maya.config.AdvertiseAddrs.HTTP
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
+
+// metaHostname defaults to the node name & then to the hostname of the
+// machine
+func (s *HTTPServer) metaHostname() string {
+	if md := s.maya.config.Metadata; md != nil && md.Hostname != "" {
+		return md.Hostname
+	}
+
+	if s.maya.config.NodeName != "" {
+		return s.maya.config.NodeName
 	}
 
-	return AnyZone, nil
+	hostname, _ := os.Hostname()
+	return hostname
 }
@@ -2,11 +2,12 @@ package server
 
 import (
 	"bytes"
-	"github.com/ugorji/go/codec"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/openebs/mayaserver/lib/config"
 )
 
 /*var (
@@ -209,20 +210,31 @@ func TestInvalidReqPathMetaViaWrap2(t *testing.T) {
 		t.Fatalf("err content type, expected: nil, got: %s", contentType)
 	}
 
-	// This should be an invalid path/method error
-	if resp.Code != 405 {
-		t.Fatalf("err http resp code, expected: 405, got: %v", resp.Code)
+	// This should be a not found error
+	if resp.Code != 404 {
+		t.Fatalf("err http resp code, expected: 404, got: %v", resp.Code)
 	}
+}
+
+func TestMetaBelowLeafViaWrap(t *testing.T) {
+
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+
+	// NOTE: `instance-id` is a leaf
+	req, err := http.NewRequest("GET",
+		"/latest/meta-data/instance-id/extra", nil)
 
-	// actuals
-	actual, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		t.Fatalf("err reading response: %v", err)
+		t.Fatalf("err: %v", err)
 	}
 
-	// compare expectations with actuals
-	if !bytes.Equal([]byte(ErrInvalidMethod), actual) {
-		t.Fatalf("bad:\nexpected:\t%q\n\nactual:\t\t%q", ErrInvalidMethod, string(actual))
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.MetaSpecificRequest)(resp, req)
+
+	if resp.Code != 404 {
+		t.Fatalf("err http resp code, expected: 404, got: %v", resp.Code)
 	}
 }
 
@@ -249,18 +261,12 @@ func TestMetaAvailZoneViaWrap(t *testing.T) {
 
 	contentType := resp.Header().Get("Content-Type")
 
-	if contentType != "application/json" {
-		t.Fatalf("Content-Type header was not 'application/json'")
+	if contentType != "text/plain" {
+		t.Fatalf("Content-Type header was not 'text/plain'")
 	}
 
 	// expectations
-	var expected bytes.Buffer
-	enc := codec.NewEncoder(&expected, jsonHandle)
-	err = enc.Encode(AnyZone)
-
-	if err != nil {
-		t.Fatalf("err while encoding: %v", err)
-	}
+	expected := []byte("global-dc1")
 
 	// actuals
 	actual, err := ioutil.ReadAll(resp.Body)
@@ -269,8 +275,8 @@ func TestMetaAvailZoneViaWrap(t *testing.T) {
 	}
 
 	// compare
-	if !bytes.Equal(expected.Bytes(), actual) {
-		t.Fatalf("bad:\nexpected:\t%q\n\nactual:\t\t%q", string(expected.Bytes()), string(actual))
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("bad:\nexpected:\t%q\n\nactual:\t\t%q", string(expected), string(actual))
 	}
 }
 
@@ -342,18 +348,12 @@ func TestMetaInstanceIDViaWrap(t *testing.T) {
 
 	contentType := resp.Header().Get("Content-Type")
 
-	if contentType != "application/json" {
-		t.Fatalf("Content-Type header was not 'application/json'")
+	if contentType != "text/plain" {
+		t.Fatalf("Content-Type header was not 'text/plain'")
 	}
 
 	// expectations
-	var expected bytes.Buffer
-	enc := codec.NewEncoder(&expected, jsonHandle)
-	err = enc.Encode(AnyInstance)
-
-	if err != nil {
-		t.Fatalf("err while encoding: %v", err)
-	}
+	expected := []byte(AnyInstance)
 
 	// actuals
 	actual, err := ioutil.ReadAll(resp.Body)
@@ -362,8 +362,8 @@ func TestMetaInstanceIDViaWrap(t *testing.T) {
 	}
 
 	// compare
-	if !bytes.Equal(expected.Bytes(), actual) {
-		t.Fatalf("bad:\nexpected:\t%q\n\nactual:\t\t%q", string(expected.Bytes()), string(actual))
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("bad:\nexpected:\t%q\n\nactual:\t\t%q", string(expected), string(actual))
 	}
 }
 
@@ -411,3 +411,64 @@ func TestInvalidReqMetaInstanceIDViaWrap(t *testing.T) {
 		t.Fatalf("bad:\nexpected:\t%q\n\nactual:\t\t%q", ErrInvalidMethod, string(actual))
 	}
 }
+
+func TestMetaDirectoryListing(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	cases := map[string]string{
+		"/latest/meta-data/":           "hostname\ninstance-id\nlocal-hostname\nlocal-ipv4\nplacement/",
+		"/latest/meta-data":            "hostname\ninstance-id\nlocal-hostname\nlocal-ipv4\nplacement/",
+		"/latest/meta-data/placement/": "availability-zone\nregion",
+	}
+
+	for path, expected := range cases {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+
+		out, err := s.Server.MetaSpecificRequest(resp, req)
+		if err != nil {
+			t.Fatalf("ERR: %s: %v", path, err)
+		}
+
+		if out != textResponse(expected) {
+			t.Fatalf("ERR: %s: expected: %q, got: %q", path, expected, out)
+		}
+	}
+}
+
+func TestMetaConfiguredIdentity(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Region = "bang"
+		mc.Datacenter = "east"
+		mc.AdvertiseAddrs.HTTP = "10.0.0.10:5656"
+		mc.Metadata = &config.Metadata{
+			InstanceID: "i-0123456789",
+			Hostname:   "maya-1",
+		}
+	})
+	defer s.Cleanup()
+
+	cases := map[string]string{
+		"/latest/meta-data/instance-id":                 "i-0123456789",
+		"/latest/meta-data/hostname":                    "maya-1",
+		"/latest/meta-data/local-hostname":              "maya-1",
+		"/latest/meta-data/local-ipv4":                  "10.0.0.10",
+		"/latest/meta-data/placement/availability-zone": "bang-east",
+		"/latest/meta-data/placement/region":            "bang",
+	}
+
+	for path, expected := range cases {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+
+		out, err := s.Server.MetaSpecificRequest(resp, req)
+		if err != nil {
+			t.Fatalf("ERR: %s: %v", path, err)
+		}
+
+		if out != textResponse(expected) {
+			t.Fatalf("ERR: %s: expected: %q, got: %q", path, expected, out)
+		}
+	}
+}
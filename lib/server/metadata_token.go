@@ -0,0 +1,120 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MetaTokenHeader is the request header that carries the session token
+	// on metadata requests
+	MetaTokenHeader = "X-aws-ec2-metadata-token"
+
+	// MetaTokenTTLHeader is the request header that carries the validity of
+	// the requested session token in seconds. The same header is set on the
+	// response.
+	MetaTokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+
+	// MaxMetaTokenTTL is the maximum validity of a session token i.e.
+	// 6 hours
+	MaxMetaTokenTTL = 21600
+)
+
+// metaTokenSigner issues stateless session tokens for the metadata endpoint.
+// A token carries its own expiry & is signed with a key that is generated
+// when the server starts. Hence nothing is stored per token & the tokens are
+// invalidated on restart.
+//
+// NOTE:
+//    A token is of the form <expiry in unix seconds>.<hex encoded hmac>
+type metaTokenSigner struct {
+	key []byte
+}
+
+func newMetaTokenSigner() (*metaTokenSigner, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate metadata token key: %v", err)
+	}
+
+	return &metaTokenSigner{key: key}, nil
+}
+
+// sign returns the hmac of the token's expiry
+func (ts *metaTokenSigner) sign(expiry string) []byte {
+	mac := hmac.New(sha256.New, ts.key)
+	mac.Write([]byte(expiry))
+	return mac.Sum(nil)
+}
+
+// issue creates a new token that is valid for the given ttl
+func (ts *metaTokenSigner) issue(ttl time.Duration) string {
+	expiry := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return expiry + "." + hex.EncodeToString(ts.sign(expiry))
+}
+
+// valid returns true if the token was signed by this server & has not
+// expired
+func (ts *metaTokenSigner) valid(token string) bool {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	sig, err := hex.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sig, ts.sign(parts[0])) {
+		return false
+	}
+
+	expiry, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() < expiry
+}
+
+// MetaTokenRequest is a http handler implementation. It issues a session
+// token for the metadata endpoint.
+//
+// NOTE:
+//    PUT /latest/api/token expects the validity of the token in seconds via
+// the X-aws-ec2-metadata-token-ttl-seconds header.
+func (s *HTTPServer) MetaTokenRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "PUT" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	ttl, err := strconv.Atoi(req.Header.Get(MetaTokenTTLHeader))
+	if err != nil || ttl < 1 || ttl > MaxMetaTokenTTL {
+		return nil, CodedError(400, "Invalid "+MetaTokenTTLHeader+": must be between 1 & "+strconv.Itoa(MaxMetaTokenTTL))
+	}
+
+	token := s.metaTokens.issue(time.Duration(ttl) * time.Second)
+
+	resp.Header().Set(MetaTokenTTLHeader, strconv.Itoa(ttl))
+	return textResponse(token), nil
+}
+
+// checkMetaToken validates the session token of a metadata request. A
+// request without a token is allowed unless the token is required by config.
+func (s *HTTPServer) checkMetaToken(req *http.Request) error {
+	token := req.Header.Get(MetaTokenHeader)
+	if token == "" {
+		if md := s.maya.config.Metadata; md != nil && md.RequireToken {
+			return CodedError(401, "Missing "+MetaTokenHeader)
+		}
+		return nil
+	}
+
+	if !s.metaTokens.valid(token) {
+		return CodedError(401, "Invalid or expired "+MetaTokenHeader)
+	}
+	return nil
+}
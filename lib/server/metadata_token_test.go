@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestMetaTokenRequest(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/latest/api/token", nil)
+	req.Header.Set(MetaTokenTTLHeader, "60")
+
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.MetaTokenRequest)(resp, req)
+
+	if resp.Code != 200 {
+		t.Fatalf("ERR: http resp code, expected: 200, got: %v", resp.Code)
+	}
+
+	if ttl := resp.Header().Get(MetaTokenTTLHeader); ttl != "60" {
+		t.Fatalf("ERR: ttl header, expected: 60, got: %s", ttl)
+	}
+
+	token := resp.Body.String()
+	if token == "" {
+		t.Fatalf("ERR: expected a non empty token")
+	}
+
+	// the token is accepted on metadata requests
+	req, _ = http.NewRequest("GET", "/latest/meta-data/instance-id", nil)
+	req.Header.Set(MetaTokenHeader, token)
+
+	if _, err := s.Server.MetaSpecificRequest(httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+}
+
+func TestInvalidMetaTokenRequest(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	cases := []struct {
+		method string
+		ttl    string
+		code   int
+	}{
+		{"GET", "60", 405},
+		{"PUT", "", 400},
+		{"PUT", "0", 400},
+		{"PUT", "21601", 400},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, "/latest/api/token", nil)
+		if c.ttl != "" {
+			req.Header.Set(MetaTokenTTLHeader, c.ttl)
+		}
+
+		_, err := s.Server.MetaTokenRequest(httptest.NewRecorder(), req)
+		if err == nil {
+			t.Fatalf("ERR: %s %s: expected an error", c.method, c.ttl)
+		}
+
+		if code := err.(HTTPCodedError).Code(); code != c.code {
+			t.Fatalf("ERR: %s %s: expected: %d, got: %d", c.method, c.ttl, c.code, code)
+		}
+	}
+}
+
+func TestMetaTokenValidation(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Metadata = &config.Metadata{RequireToken: true}
+	})
+	defer s.Cleanup()
+
+	expired := s.Server.metaTokens.issue(-time.Second)
+
+	// a token whose expiry is tampered with fails the signature check
+	valid := s.Server.metaTokens.issue(time.Minute)
+	forged := "9999999999" + valid[strings.Index(valid, "."):]
+
+	// a request without token, with an unknown token, with an expired token
+	// & with a forged token are all rejected in strict mode
+	for _, token := range []string{"", "oddy", expired, forged} {
+		req, _ := http.NewRequest("GET", "/latest/meta-data/instance-id", nil)
+		if token != "" {
+			req.Header.Set(MetaTokenHeader, token)
+		}
+
+		out, err := s.Server.MetaSpecificRequest(httptest.NewRecorder(), req)
+		if err == nil {
+			t.Fatalf("ERR: token %q: expected an error", token)
+		}
+
+		if code := err.(HTTPCodedError).Code(); code != 401 {
+			t.Fatalf("ERR: token %q: expected: 401, got: %d", token, code)
+		}
+
+		if out != nil {
+			t.Fatalf("Service must not return any value, for invalid token")
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/openebs/maya/orchprovider"
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/maya/volumes/profile/volumeprovisioner"
+	"github.com/openebs/maya/volumes/provisioner"
+)
+
+// PluginList is the collection of plugins that are registered with this
+// maya api server
+type PluginList struct {
+	Provisioners  []ProvisionerPlugin  `json:"provisioners"`
+	Orchestrators []OrchestratorPlugin `json:"orchestrators"`
+}
+
+// ProvisionerPlugin describes a registered persistent volume provisioner
+type ProvisionerPlugin struct {
+	Label string `json:"label"`
+	Name  string `json:"name"`
+
+	// Capabilities are the volume operations supported by the provisioner
+	Capabilities ProvisionerCapabilities `json:"capabilities"`
+
+	// ProfileDefaults are the values that are applied to a VSM if the
+	// claim does not specify them
+	ProfileDefaults map[string]string `json:"profileDefaults,omitempty"`
+
+	// Error is set if the provisioner could not be instantiated
+	Error string `json:"error,omitempty"`
+}
+
+// ProvisionerCapabilities are the volume operations of a provisioner
+type ProvisionerCapabilities struct {
+	Adder   bool `json:"adder"`
+	Reader  bool `json:"reader"`
+	Lister  bool `json:"lister"`
+	Remover bool `json:"remover"`
+}
+
+// OrchestratorPlugin describes a registered orchestration provider
+type OrchestratorPlugin struct {
+	Label  string `json:"label"`
+	Name   string `json:"name"`
+	Region string `json:"region"`
+
+	// StorageOps is true if the orchestrator can operate on storage
+	StorageOps bool `json:"storageOps"`
+
+	// Error is set if the orchestrator could not be instantiated
+	Error string `json:"error,omitempty"`
+}
+
+// PluginsRequest is a http handler implementation. It lists the plugins
+// registered with this maya api server.
+func (s *HTTPServer) PluginsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	l := &PluginList{
+		Provisioners:  []ProvisionerPlugin{},
+		Orchestrators: []OrchestratorPlugin{},
+	}
+
+	for _, name := range s.maya.provisioners {
+		if provisioner.HasVolumeProvisioner(name) {
+			l.Provisioners = append(l.Provisioners, provisionerPlugin(name))
+		}
+	}
+
+	for _, name := range s.maya.orchestrators {
+		if orchprovider.HasOrchestrator(name) {
+			l.Orchestrators = append(l.Orchestrators, orchestratorPlugin(name))
+		}
+	}
+
+	return l, nil
+}
+
+// provisionerPlugin introspects the provisioner with its default profile
+func provisionerPlugin(name v1.VolumeProvisionerRegistry) ProvisionerPlugin {
+	p := ProvisionerPlugin{Name: string(name)}
+
+	pvp, err := provisioner.GetVolumeProvisionerByName(name)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	p.Label = pvp.Label()
+	p.Name = pvp.Name()
+
+	// Lister & Remover are available only once the profile is set
+	pvc := &v1.PersistentVolumeClaim{}
+	if _, err := pvp.Profile(pvc); err != nil {
+		p.Error = err.Error()
+	}
+
+	_, p.Capabilities.Adder = pvp.Adder()
+	_, p.Capabilities.Reader = pvp.Reader()
+	if _, ok, err := pvp.Lister(); ok && err == nil {
+		p.Capabilities.Lister = true
+	}
+	if _, ok, err := pvp.Remover(); ok && err == nil {
+		p.Capabilities.Remover = true
+	}
+
+	p.ProfileDefaults = profileDefaults(pvc)
+	return p
+}
+
+// profileDefaults returns the values of the default profile. The values
+// that the profile can not provide are left out.
+func profileDefaults(pvc *v1.PersistentVolumeClaim) map[string]string {
+	profile, err := volumeprovisioner.GetVolProProfileByPVC(pvc)
+	if err != nil {
+		return nil
+	}
+
+	defaults := map[string]string{
+		"profile": string(profile.Name()),
+	}
+	if o, ok, err := profile.Orchestrator(); ok && err == nil {
+		defaults["orchestrator"] = string(o)
+	}
+	if n, err := profile.ControllerCount(); err == nil {
+		defaults["controllerCount"] = strconv.Itoa(n)
+	}
+	if img, ok, err := profile.ControllerImage(); ok && err == nil {
+		defaults["controllerImage"] = img
+	}
+	if n, err := profile.ReplicaCount(); err == nil {
+		defaults["replicaCount"] = strconv.Itoa(n)
+	}
+	if img, err := profile.ReplicaImage(); err == nil {
+		defaults["replicaImage"] = img
+	}
+	if size, err := profile.StorageSize(); err == nil {
+		defaults["storageSize"] = size
+	}
+	if path, err := profile.PersistentPath(); err == nil {
+		defaults["persistentPath"] = path
+	}
+
+	return defaults
+}
+
+// orchestratorPlugin introspects the orchestrator
+func orchestratorPlugin(name v1.OrchProviderRegistry) OrchestratorPlugin {
+	o := OrchestratorPlugin{Name: string(name)}
+
+	orch, err := orchprovider.GetOrchestrator(name)
+	if err != nil {
+		o.Error = err.Error()
+		return o
+	}
+
+	o.Label = orch.Label()
+	o.Name = orch.Name()
+	o.Region = orch.Region()
+	_, o.StorageOps = orch.StorageOps()
+
+	return o
+}
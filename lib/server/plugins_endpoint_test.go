@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+)
+
+func TestPluginsRequest(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	req, _ := http.NewRequest("GET", "/latest/plugins", nil)
+
+	out, err := s.Server.PluginsRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	l := out.(*PluginList)
+	if len(l.Provisioners) != 1 || l.Provisioners[0].Name != string(v1.JivaVolumeProvisioner) {
+		t.Fatalf("ERR: expected the jiva provisioner, got: %+v", l.Provisioners)
+	}
+
+	caps := l.Provisioners[0].Capabilities
+	if !caps.Adder || !caps.Reader {
+		t.Fatalf("ERR: expected jiva to support add & read, got: %+v", caps)
+	}
+
+	if len(l.Orchestrators) != 2 {
+		t.Fatalf("ERR: expected 2 orchestrators, got: %+v", l.Orchestrators)
+	}
+
+	names := map[string]bool{}
+	for _, o := range l.Orchestrators {
+		names[o.Name] = true
+	}
+	if !names[string(v1.K8sOrchestrator)] || !names[string(v1.NomadOrchestrator)] {
+		t.Fatalf("ERR: expected kubernetes & nomad, got: %+v", l.Orchestrators)
+	}
+
+	req, _ = http.NewRequest("POST", "/latest/plugins", nil)
+	if _, err := s.Server.PluginsRequest(httptest.NewRecorder(), req); err == nil || err.Error() != ErrInvalidMethod {
+		t.Fatalf("ERR: expected: %v, got: %v", ErrInvalidMethod, err)
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/event"
+	"github.com/openebs/mayaserver/lib/quota"
+)
+
+// QuotaStatus is the quota of a namespace along with its current usage
+type QuotaStatus struct {
+	Namespace string       `json:"namespace"`
+	Quota     *quota.Quota `json:"quota,omitempty"`
+	Usage     quota.Usage  `json:"usage"`
+}
+
+// admitVSMAdd checks the precondition & the quota of a VSM add. Every add
+// is serialized with the other VSM mutations. The returned func releases the
+// lock once the add is done.
+//
+// NOTE:
+//    The usage of a namespace is derived from the listed VSMs. Holding the
+// lock till the VSM is added ensures two adds do not exceed the quota
+// together.
+func (s *HTTPServer) admitVSMAdd(req *http.Request, pvc *v1.PersistentVolumeClaim) (func(), error) {
+	ns := v1.GetOrchestratorNS(pvc.Labels)
+	q := s.maya.quotas.Get(ns)
+
+	s.vsmLock.Lock()
+	unlock := s.vsmLock.Unlock
+
+	if err := checkIfNoneMatch(req, pvc.Name); err != nil {
+		unlock()
+		return nil, err
+	}
+
+	if q != nil {
+		if err := checkQuota(q, pvc); err != nil {
+			unlock()
+			if coded, ok := err.(HTTPCodedError); ok && coded.Code() == 403 {
+				s.maya.events.Record(event.Warning, "QuotaExceeded", pvc.Name, err.Error())
+			}
+			return nil, err
+		}
+	}
+
+	return unlock, nil
+}
+
+// checkQuota returns a 403 coded error if the VSM of the claim would exceed
+// the quota of its namespace
+func checkQuota(q *quota.Quota, pvc *v1.PersistentVolumeClaim) error {
+	r, err := quotaRequest(pvc)
+	if err != nil {
+		return CodedError(422, err.Error())
+	}
+
+	// The limits of a single VSM do not need the usage
+	if err := q.CheckVolume(r); err != nil {
+		return CodedError(403, err.Error())
+	}
+
+	pvl, err := listVSMs()
+	if err != nil {
+		return fmt.Errorf("Failed to compute the usage of namespace '%s': %v", q.Namespace, err)
+	}
+
+	if err := q.Check(namespaceUsage(pvl)[q.Namespace], r); err != nil {
+		return CodedError(403, err.Error())
+	}
+	return nil
+}
+
+// quotaRequest derives the capacity & the replicas of the VSM of the claim.
+// The defaults of the volume provisioner apply if the claim does not set
+// these.
+func quotaRequest(pvc *v1.PersistentVolumeClaim) (quota.Request, error) {
+	size, err := v1.ParseQuantity(v1.GetPVPStorageSize(pvc.Labels))
+	if err != nil {
+		return quota.Request{}, fmt.Errorf("Invalid storage size: %v", err)
+	}
+
+	replicas, err := v1.GetPVPReplicaCountInt(pvc.Labels)
+	if err != nil {
+		return quota.Request{}, fmt.Errorf("Invalid replica count: %v", err)
+	}
+
+	return quota.Request{CapacityBytes: size.Value(), Replicas: replicas}, nil
+}
+
+// namespaceUsage sums up the listed VSMs per namespace
+func namespaceUsage(pvl *v1.PersistentVolumeList) map[string]quota.Usage {
+	usage := map[string]quota.Usage{}
+	if pvl == nil {
+		return usage
+	}
+
+	for i := range pvl.Items {
+		pv := &pvl.Items[i]
+		ns := vsmNamespace(pv)
+
+		u := usage[ns]
+		u.Volumes++
+		u.CapacityBytes += mapiv1.FromPersistentVolume(pv).Spec.CapacityBytes
+		usage[ns] = u
+	}
+	return usage
+}
+
+// quotaStatuses reports the usage of every namespace that either has a quota
+// or has VSMs, sorted by namespace
+func quotaStatuses(quotas []*quota.Quota, pvl *v1.PersistentVolumeList) []QuotaStatus {
+	usage := namespaceUsage(pvl)
+
+	byNS := map[string]*QuotaStatus{}
+	for _, q := range quotas {
+		byNS[q.Namespace] = &QuotaStatus{Namespace: q.Namespace, Quota: q}
+	}
+	for ns, u := range usage {
+		if _, ok := byNS[ns]; !ok {
+			byNS[ns] = &QuotaStatus{Namespace: ns}
+		}
+		byNS[ns].Usage = u
+	}
+
+	statuses := make([]QuotaStatus, 0, len(byNS))
+	for _, st := range byNS {
+		statuses = append(statuses, *st)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Namespace < statuses[j].Namespace
+	})
+	return statuses
+}
+
+// QuotasRequest is a http handler implementation. It deals with the quotas
+// of the namespaces.
+//
+// NOTE:
+//    GET /latest/quotas reports the quota & the usage of every namespace
+// while GET /latest/quotas/<namespace> reports a single namespace. PUT
+// /latest/quotas/<namespace> sets the quota of a namespace & DELETE
+// /latest/quotas/<namespace> reverts it to the configured quota. Setting &
+// deleting a quota require the management token.
+func (s *HTTPServer) QuotasRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	ns := strings.Trim(strings.TrimPrefix(req.URL.Path, "/latest/quotas"), "/")
+
+	switch {
+	case req.Method == "GET":
+		return s.quotaRead(resp, req, ns)
+	case req.Method == "PUT" && ns != "":
+		return s.quotaSet(resp, req, ns)
+	case req.Method == "DELETE" && ns != "":
+		return s.quotaDelete(resp, req, ns)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+// quotaRead is the http handler that reports the quotas along with their
+// usage
+func (s *HTTPServer) quotaRead(resp http.ResponseWriter, req *http.Request, ns string) (interface{}, error) {
+	pvl, err := listVSMs()
+	if err != nil {
+		return nil, err
+	}
+
+	if ns == "" {
+		return quotaStatuses(s.maya.quotas.List(), pvl), nil
+	}
+
+	st := QuotaStatus{
+		Namespace: ns,
+		Quota:     s.maya.quotas.Get(ns),
+		Usage:     namespaceUsage(pvl)[ns],
+	}
+	return st, nil
+}
+
+// quotaSet is the http handler that sets the quota of a namespace
+func (s *HTTPServer) quotaSet(resp http.ResponseWriter, req *http.Request, ns string) (interface{}, error) {
+	if err := s.checkACL(req); err != nil {
+		return nil, err
+	}
+
+	q := quota.Quota{}
+	if err := s.decodeBody(req, &q); err != nil {
+		return nil, err
+	}
+
+	if q.Namespace != "" && q.Namespace != ns {
+		return nil, CodedError(400, fmt.Sprintf("Quota namespace '%s' does not match '%s'", q.Namespace, ns))
+	}
+	q.Namespace = ns
+
+	if err := q.Validate(); err != nil {
+		return nil, CodedError(400, err.Error())
+	}
+
+	if err := s.maya.quotas.Set(&q); err != nil {
+		return nil, err
+	}
+
+	return s.maya.quotas.Get(ns), nil
+}
+
+// quotaDelete is the http handler that deletes the quota of a namespace
+// that was set through the API
+func (s *HTTPServer) quotaDelete(resp http.ResponseWriter, req *http.Request, ns string) (interface{}, error) {
+	if err := s.checkACL(req); err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.maya.quotas.Delete(ns)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, CodedError(404, fmt.Sprintf("Quota of namespace '%s' was not set through the API", ns))
+	}
+
+	resp.WriteHeader(204)
+	return nil, nil
+}
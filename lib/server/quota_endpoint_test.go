@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/event"
+	"github.com/openebs/mayaserver/lib/quota"
+)
+
+func TestQuotaEnforced(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Quotas = []*config.Quota{
+			{Namespace: "team-a", MaxReplicas: 2},
+		}
+	})
+	defer s.Cleanup()
+
+	claim := func(replicas string) *bytes.Buffer {
+		return bytes.NewBufferString(`{"metadata": {"name": "vol1", "labels": {
+			"orchprovider.mapi.openebs.io/ns": "team-a",
+			"volumeprovisioner.mapi.openebs.io/replica-count": "` + replicas + `"}}}`)
+	}
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/latest/volumes/", claim("3"))
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.VSMSpecificRequest)(resp, req)
+
+	if resp.Code != 403 {
+		t.Fatalf("ERR: http resp code, expected: 403, got: %v", resp.Code)
+	}
+	if !strings.Contains(resp.Body.String(), "at most 2 replicas") {
+		t.Fatalf("ERR: expected the reason, got: %s", resp.Body.String())
+	}
+	if events := s.Maya.events.List(event.Filter{Volume: "vol1"}); len(events) != 1 || events[0].Reason != "QuotaExceeded" {
+		t.Fatalf("ERR: expected a QuotaExceeded event, got: %+v", events)
+	}
+
+	// There is no orchestrator to compute the usage
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/latest/vsms/", claim("2"))
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.TypedVSMRequest)(resp, req)
+
+	if resp.Code != 500 {
+		t.Fatalf("ERR: http resp code, expected: 500, got: %v", resp.Code)
+	}
+}
+
+func TestQuotasRequest(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "s3cr3t"}
+		mc.Quotas = []*config.Quota{
+			{Namespace: "team-a", MaxVolumes: 2},
+		}
+	})
+	defer s.Cleanup()
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		token  string
+		code   int
+	}{
+		{"PUT", "/latest/quotas/team-a", `{"maxVolumes": 4}`, "", 403},
+		{"PUT", "/latest/quotas/team-a", `{"maxCapacity": "lots"}`, "s3cr3t", 400},
+		{"PUT", "/latest/quotas/team-a", `{"namespace": "team-b"}`, "s3cr3t", 400},
+		{"PUT", "/latest/quotas/team-a", `{"maxVolumes": 4, "maxCapacity": "10G"}`, "s3cr3t", 200},
+		{"PUT", "/latest/quotas", `{"maxVolumes": 4}`, "s3cr3t", 405},
+		{"DELETE", "/latest/quotas/team-a", "", "s3cr3t", 204},
+		{"DELETE", "/latest/quotas/team-a", "", "s3cr3t", 404},
+	}
+
+	for _, c := range cases {
+		if c.code == 204 {
+			if q := s.Maya.quotas.Get("team-a"); q.MaxVolumes != 4 || q.Source != quota.SourceAPI {
+				t.Fatalf("ERR: expected the quota to be set, got: %+v", q)
+			}
+		}
+
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(c.method, c.path, bytes.NewBufferString(c.body))
+		if c.token != "" {
+			req.Header.Set(MayaTokenHeader, c.token)
+		}
+		s.Server.wrap(RequestCounter, RequestDuration, s.Server.QuotasRequest)(resp, req)
+
+		if resp.Code != c.code {
+			t.Fatalf("ERR: %s %s %s: http resp code, expected: %d, got: %v", c.method, c.path, c.body, c.code, resp.Code)
+		}
+	}
+
+	// The configured quota applies again
+	if q := s.Maya.quotas.Get("team-a"); q.MaxVolumes != 2 || q.Source != quota.SourceConfig {
+		t.Fatalf("ERR: expected the configured quota, got: %+v", q)
+	}
+}
+
+func TestQuotaStatuses(t *testing.T) {
+	makePV := func(name, ns, size string) v1.PersistentVolume {
+		pv := v1.PersistentVolume{}
+		pv.Name = name
+		pv.Labels = map[string]string{string(v1.OrchNSLbl): ns}
+		pv.Annotations = map[string]string{string(v1.VolumeSizeAPILbl): size}
+		return pv
+	}
+
+	pvl := &v1.PersistentVolumeList{Items: []v1.PersistentVolume{
+		makePV("vol1", "team-a", "1G"),
+		makePV("vol2", "team-a", "2G"),
+		makePV("vol3", "team-b", "1G"),
+	}}
+	quotas := []*quota.Quota{
+		{Namespace: "team-a", MaxVolumes: 2},
+		{Namespace: "team-c", MaxVolumes: 1},
+	}
+
+	st := quotaStatuses(quotas, pvl)
+	if len(st) != 3 {
+		t.Fatalf("ERR: expected 3 namespaces, got: %+v", st)
+	}
+	if st[0].Namespace != "team-a" || st[0].Quota == nil || st[0].Usage.Volumes != 2 || st[0].Usage.CapacityBytes != 3e9 {
+		t.Fatalf("ERR: unexpected status: %+v", st[0])
+	}
+	if st[1].Namespace != "team-b" || st[1].Quota != nil || st[1].Usage.Volumes != 1 {
+		t.Fatalf("ERR: unexpected status: %+v", st[1])
+	}
+	if st[2].Namespace != "team-c" || st[2].Usage.Volumes != 0 {
+		t.Fatalf("ERR: unexpected status: %+v", st[2])
+	}
+
+	// team-a is at its quota
+	err := quotas[0].Check(namespaceUsage(pvl)["team-a"], quota.Request{CapacityBytes: 1e9, Replicas: 1})
+	if err == nil {
+		t.Fatalf("ERR: expected team-a to exceed its quota")
+	}
+}
@@ -1,17 +1,29 @@
 package server
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"net/http"
+	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/hashicorp/logutils"
 	"github.com/openebs/maya/orchprovider"
 	"github.com/openebs/maya/orchprovider/k8s/v1"
 	"github.com/openebs/maya/orchprovider/nomad/v1"
 	"github.com/openebs/maya/types/v1"
 	"github.com/openebs/maya/volumes/provisioner"
 	"github.com/openebs/maya/volumes/provisioner/jiva"
+	"github.com/openebs/mayaserver/lib/admission"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/audit"
 	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/event"
+	"github.com/openebs/mayaserver/lib/loghelper"
+	"github.com/openebs/mayaserver/lib/quota"
+	"github.com/openebs/mayaserver/lib/webhook"
 )
 
 // MayaApiServer is a long running stateless daemon that runs
@@ -24,6 +36,58 @@ type MayaApiServer struct {
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
+
+	// startTime is when this maya api server was started
+	startTime time.Time
+
+	// provisioners & orchestrators are the plugins that are bootstrapped by
+	// this maya api server
+	provisioners  []v1.VolumeProvisionerRegistry
+	orchestrators []v1.OrchProviderRegistry
+
+	// logRegistrar buffers the logs & streams them to the monitors
+	logRegistrar *loghelper.LogRegistrar
+
+	// logFilter filters the logs as per the current log level
+	logFilter *logutils.LevelFilter
+
+	// revertTimer reverts a temporary change of the log level to
+	// revertLevel at revertAt. logLevelGen is bumped on every change of the
+	// level so that a timer which fired late does not revert a newer level.
+	logLevelLock sync.Mutex
+	revertTimer  *time.Timer
+	revertLevel  string
+	revertAt     *time.Time
+	logLevelGen  uint64
+
+	// audit records the mutating API calls. It is nil if auditing is not
+	// enabled.
+	audit *audit.Log
+
+	// webhooks notifies the configured webhooks of the volume lifecycle
+	// events. It is nil if no webhook is configured.
+	webhooks *webhook.Dispatcher
+
+	// events are the recent events of this maya api server & its VSMs
+	events *event.Store
+
+	// quotas limit the VSMs that may be provisioned per namespace
+	quotas *quota.Store
+
+	// admission is the chain of policies that admit a claim before its VSM
+	// is provisioned
+	admission *admission.Chain
+
+	// storageClasses are the configured storage classes by their name
+	storageClasses map[string]*mapiv1.StorageClass
+
+	// volumeStates are the health & capacity of the VSMs as of the last
+	// metrics collection. These detect the degraded & resized VSMs.
+	volumeStates map[string]volumeState
+
+	// vsmIndex is bumped on every change of the VSMs. Blocking queries wait
+	// on it.
+	vsmIndex *vsmIndex
 }
 
 // NewMayaApiServer is used to create a new maya api server
@@ -35,6 +99,9 @@ func NewMayaApiServer(config *config.MayaConfig, logOutput io.Writer) (*MayaApiS
 		logger:     log.New(logOutput, "", log.LstdFlags|log.Lmicroseconds),
 		logOutput:  logOutput,
 		shutdownCh: make(chan struct{}),
+		startTime:  time.Now(),
+		events:     event.NewStore(event.DefaultMaxEvents),
+		vsmIndex:   newVSMIndex(),
 	}
 
 	err := ms.BootstrapPlugins()
@@ -42,6 +109,27 @@ func NewMayaApiServer(config *config.MayaConfig, logOutput io.Writer) (*MayaApiS
 		return nil, err
 	}
 
+	// The audit log & the webhook deliveries that are already set up are
+	// closed if a later step fails
+	setups := []func() error{
+		ms.setupAudit,
+		ms.setupWebhooks,
+		ms.setupQuotas,
+		ms.setupAdmission,
+		ms.setupStorageClasses,
+	}
+	for _, setup := range setups {
+		if err := setup(); err != nil {
+			ms.closeResources()
+			return nil, err
+		}
+	}
+
+	ms.events.Recordf(event.Normal, "Started", "", "Maya api server %s started", ms.config.NodeName)
+
+	// Refresh the per volume gauges in the background
+	go ms.collectVolumeMetrics(volumeMetricsInterval)
+
 	return ms, nil
 }
 
@@ -80,6 +168,7 @@ func (ms *MayaApiServer) BootstrapPlugins() error {
 				return jiva.NewJivaProvisioner(label, name)
 			})
 	}
+	ms.provisioners = append(ms.provisioners, v1.JivaVolumeProvisioner)
 
 	// Register orchestrator(s)
 	isK8sOrchReg := orchprovider.HasOrchestrator(v1.K8sOrchestrator)
@@ -93,6 +182,7 @@ func (ms *MayaApiServer) BootstrapPlugins() error {
 				return k8s.NewK8sOrchestrator(label, name)
 			})
 	}
+	ms.orchestrators = append(ms.orchestrators, v1.K8sOrchestrator)
 
 	isNomadOrchReg := orchprovider.HasOrchestrator(v1.NomadOrchestrator)
 	if !isNomadOrchReg {
@@ -105,10 +195,26 @@ func (ms *MayaApiServer) BootstrapPlugins() error {
 				return nomad.NewNomadOrchestrator(label, name)
 			})
 	}
+	ms.orchestrators = append(ms.orchestrators, v1.NomadOrchestrator)
 
 	return nil
 }
 
+// SetLogRegistrar sets the log sink that is streamed by the monitor
+// endpoint. It must be set before the HTTP server is started.
+func (ms *MayaApiServer) SetLogRegistrar(logRegistrar *loghelper.LogRegistrar) {
+	ms.logRegistrar = logRegistrar
+}
+
+// SetLogFilter sets the filter whose level can be changed via the log-level
+// endpoint
+func (ms *MayaApiServer) SetLogFilter(logFilter *logutils.LevelFilter) {
+	ms.logLevelLock.Lock()
+	defer ms.logLevelLock.Unlock()
+
+	ms.logFilter = logFilter
+}
+
 // Shutdown is used to terminate MayaServer.
 func (ms *MayaApiServer) Shutdown() error {
 
@@ -121,6 +227,8 @@ func (ms *MayaApiServer) Shutdown() error {
 		return nil
 	}
 
+	ms.closeResources()
+
 	ms.logger.Println("[INFO] maya api server: shutdown complete")
 	ms.shutdown = true
 
@@ -129,6 +237,196 @@ func (ms *MayaApiServer) Shutdown() error {
 	return nil
 }
 
+// closeResources closes the audit log & stops the webhook deliveries if these
+// are set up
+func (ms *MayaApiServer) closeResources() {
+	if ms.audit != nil {
+		if err := ms.audit.Close(); err != nil {
+			ms.logger.Printf("[ERR] maya api server: failed to close audit log: %v", err)
+		}
+	}
+
+	if ms.webhooks != nil {
+		ms.webhooks.Stop()
+	}
+}
+
+// setupAudit opens the audit log if auditing is enabled
+func (ms *MayaApiServer) setupAudit() error {
+	conf := ms.config.Audit
+	if conf == nil || !conf.Enabled {
+		return nil
+	}
+
+	dir := conf.Path
+	if dir == "" {
+		if ms.config.DataDir == "" {
+			return fmt.Errorf("audit requires either the audit path or data_dir")
+		}
+		dir = filepath.Join(ms.config.DataDir, "audit")
+	}
+
+	l, err := audit.NewLog(dir, conf.MaxBytes, conf.MaxFiles)
+	if err != nil {
+		return err
+	}
+	ms.audit = l
+
+	return nil
+}
+
+// setupWebhooks starts the delivery of the volume lifecycle events if any
+// webhook is configured. The deliveries are queued under the data dir.
+func (ms *MayaApiServer) setupWebhooks() error {
+	if len(ms.config.Webhooks) == 0 {
+		return nil
+	}
+
+	if ms.config.DataDir == "" {
+		return fmt.Errorf("webhooks require data_dir")
+	}
+
+	hooks := make([]*webhook.Hook, 0, len(ms.config.Webhooks))
+	for _, w := range ms.config.Webhooks {
+		hook := &webhook.Hook{
+			Name:   w.Name,
+			URL:    w.URL,
+			Secret: w.Secret,
+		}
+		for _, e := range w.Events {
+			hook.Events = append(hook.Events, webhook.EventType(e))
+		}
+		hooks = append(hooks, hook)
+	}
+
+	d, err := webhook.NewDispatcher(filepath.Join(ms.config.DataDir, "webhooks"), hooks, ms.logger)
+	if err != nil {
+		return err
+	}
+	d.Start()
+	ms.webhooks = d
+
+	return nil
+}
+
+// setupQuotas loads the configured quotas. The quotas that are set through
+// the API are persisted under the data dir if it is set.
+func (ms *MayaApiServer) setupQuotas() error {
+	quotas := make([]*quota.Quota, 0, len(ms.config.Quotas))
+	for _, q := range ms.config.Quotas {
+		quotas = append(quotas, &quota.Quota{
+			Namespace:   q.Namespace,
+			MaxVolumes:  q.MaxVolumes,
+			MaxCapacity: q.MaxCapacity,
+			MaxReplicas: q.MaxReplicas,
+		})
+	}
+
+	path := ""
+	if ms.config.DataDir != "" {
+		path = filepath.Join(ms.config.DataDir, "quotas.json")
+	}
+
+	s, err := quota.NewStore(path, quotas)
+	if err != nil {
+		return err
+	}
+	ms.quotas = s
+
+	return nil
+}
+
+// setupAdmission builds the chain of the configured admission policies. The
+// policies of each type run in the order they are configured.
+func (ms *MayaApiServer) setupAdmission() error {
+	chain := &admission.Chain{}
+
+	for _, conf := range ms.config.Admissions {
+		selector := admission.Selector{
+			Namespaces: conf.Namespaces,
+			Names:      conf.Names,
+		}
+		if err := selector.Validate(); err != nil {
+			return fmt.Errorf("admission '%s': %v", conf.Name, err)
+		}
+
+		if conf.Type != admission.Mutating && conf.Type != admission.Validating {
+			return fmt.Errorf("admission '%s': invalid type '%s'", conf.Name, conf.Type)
+		}
+
+		// An admission webhook
+		if conf.URL != "" {
+			switch conf.FailurePolicy {
+			case "", admission.FailurePolicyFail, admission.FailurePolicyIgnore:
+			default:
+				return fmt.Errorf("admission '%s': invalid failure policy '%s'", conf.Name, conf.FailurePolicy)
+			}
+
+			timeout := conf.Timeout
+			if timeout == 0 {
+				timeout = admission.DefaultWebhookTimeout
+			}
+
+			p := &admission.WebhookPolicy{
+				PolicyName:    conf.Name,
+				Selector:      selector,
+				URL:           conf.URL,
+				Type:          conf.Type,
+				FailurePolicy: conf.FailurePolicy,
+				Client:        &http.Client{Timeout: timeout},
+			}
+			if conf.Type == admission.Mutating {
+				chain.Mutators = append(chain.Mutators, p)
+			} else {
+				chain.Validators = append(chain.Validators, p)
+			}
+			continue
+		}
+
+		if conf.Type == admission.Mutating {
+			if len(conf.Labels) == 0 {
+				return fmt.Errorf("admission '%s': a mutating policy requires either labels or url", conf.Name)
+			}
+			chain.Mutators = append(chain.Mutators, &admission.LabelPolicy{
+				PolicyName: conf.Name,
+				Selector:   selector,
+				Labels:     conf.Labels,
+				Overwrite:  conf.Overwrite,
+			})
+			continue
+		}
+
+		if len(conf.Forbid) == 0 && len(conf.Require) == 0 {
+			return fmt.Errorf("admission '%s': a validating policy requires either forbid, require or url", conf.Name)
+		}
+		chain.Validators = append(chain.Validators, &admission.RulePolicy{
+			PolicyName: conf.Name,
+			Selector:   selector,
+			Forbid:     conf.Forbid,
+			Require:    conf.Require,
+			Message:    conf.Message,
+		})
+	}
+
+	ms.admission = chain
+	return nil
+}
+
+// setupStorageClasses validates the configured storage classes
+func (ms *MayaApiServer) setupStorageClasses() error {
+	ms.storageClasses = map[string]*mapiv1.StorageClass{}
+
+	for _, conf := range ms.config.StorageClasses {
+		sc := mapiv1.NewStorageClass(conf.Name, storageClassParameters(conf))
+		if err := sc.Validate(); err != nil {
+			return err
+		}
+		ms.storageClasses[conf.Name] = sc
+	}
+
+	return nil
+}
+
 // Leave is used gracefully exit.
 func (ms *MayaApiServer) Leave() error {
 
@@ -1,69 +1,11 @@
 package server
 
 import (
-	"fmt"
-	"io/ioutil"
-	"net"
-	"os"
 	"testing"
 
 	"github.com/openebs/mayaserver/lib/config"
 )
 
-func getPort() int {
-	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
-	if err != nil {
-		panic(err)
-	}
-
-	l, err := net.ListenTCP("tcp", addr)
-	if err != nil {
-		panic(err)
-	}
-	defer l.Close()
-	return l.Addr().(*net.TCPAddr).Port
-}
-
-func tmpDir(t testing.TB) string {
-	dir, err := ioutil.TempDir("", "mapiserver")
-	if err != nil {
-		t.Fatalf("err: %v", err)
-	}
-	return dir
-}
-
-func makeMayaServer(t testing.TB, fnmc func(*config.MayaConfig)) (string, *MayaApiServer) {
-	dir := tmpDir(t)
-
-	// Customize the server configuration
-	conf := config.DefaultMayaConfig()
-
-	// Set the data_dir
-	conf.DataDir = dir
-
-	// Bind and set ports
-	conf.BindAddr = "127.0.0.1"
-	conf.Ports = &config.Ports{
-		HTTP: getPort(),
-	}
-	conf.NodeName = fmt.Sprintf("Node %d", conf.Ports.HTTP)
-
-	if fnmc != nil {
-		fnmc(conf)
-	}
-
-	if err := conf.NormalizeAddrs(); err != nil {
-		t.Fatalf("error normalizing config: %v", err)
-	}
-
-	maya, err := NewMayaApiServer(conf, os.Stderr)
-	if err != nil {
-		os.RemoveAll(dir)
-		t.Fatalf("err: %v", err)
-	}
-	return dir, maya
-}
-
 func TestMayaServerConfig(t *testing.T) {
 	conf := config.DefaultMayaConfig()
 
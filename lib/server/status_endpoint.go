@@ -0,0 +1,153 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openebs/maya/orchprovider"
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/maya/volumes/provisioner"
+)
+
+// orchDialTimeout is the timeout to connect to the orchestrator while
+// checking its reachability
+const orchDialTimeout = 2 * time.Second
+
+// Health is the liveness of maya api server
+type Health struct {
+	Status string `json:"status"`
+}
+
+// Readiness is the readiness of maya api server along with the checks it
+// is derived from
+type Readiness struct {
+	Ready  bool             `json:"ready"`
+	Checks []ReadinessCheck `json:"checks"`
+}
+
+// ReadinessCheck is the outcome of a single readiness check
+type ReadinessCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// StatusSpecificRequest is a http handler implementation. It deals with the
+// liveness & readiness probes.
+//
+// NOTE:
+//    GET /latest/status/health is served as long as the server is up while
+// GET /latest/status/ready fails with 503 if any of the readiness checks fail.
+func (s *HTTPServer) StatusSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	switch strings.TrimPrefix(req.URL.Path, "/latest/status") {
+	case "/health":
+		return &Health{Status: "ok"}, nil
+	case "/ready":
+		return s.statusReady()
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+// statusReady runs the readiness checks
+func (s *HTTPServer) statusReady() (*Readiness, error) {
+	r := &Readiness{
+		Ready: true,
+		Checks: []ReadinessCheck{
+			registryCheck("provisioner/"+string(v1.JivaVolumeProvisioner),
+				provisioner.HasVolumeProvisioner(v1.JivaVolumeProvisioner)),
+			registryCheck("orchestrator/"+string(v1.K8sOrchestrator),
+				orchprovider.HasOrchestrator(v1.K8sOrchestrator)),
+			registryCheck("orchestrator/"+string(v1.NomadOrchestrator),
+				orchprovider.HasOrchestrator(v1.NomadOrchestrator)),
+			orchReachableCheck(v1.GetOrchestratorName(nil)),
+		},
+	}
+
+	var failed []string
+	for _, c := range r.Checks {
+		if !c.OK {
+			r.Ready = false
+			failed = append(failed, c.Name+": "+c.Message)
+		}
+	}
+
+	if !r.Ready {
+		return nil, CodedError(503, "Not ready: "+strings.Join(failed, ", "))
+	}
+
+	return r, nil
+}
+
+// registryCheck checks if a plugin is registered
+func registryCheck(name string, registered bool) ReadinessCheck {
+	c := ReadinessCheck{Name: name, OK: registered}
+	if !registered {
+		c.Message = "not registered"
+	}
+	return c
+}
+
+// orchReachableCheck checks if a TCP connection can be made to the given
+// orchestrator
+func orchReachableCheck(name v1.OrchProviderRegistry) ReadinessCheck {
+	c := ReadinessCheck{Name: "reachable/" + string(name)}
+
+	addr, err := orchAddress(name)
+	if err != nil {
+		c.Message = err.Error()
+		return c
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, orchDialTimeout)
+	if err != nil {
+		c.Message = err.Error()
+		return c
+	}
+	conn.Close()
+
+	c.OK = true
+	c.Message = addr
+	return c
+}
+
+// orchAddress returns the host:port of the given orchestrator. Kubernetes is
+// reached from within the cluster while Nomad is reached at NOMAD_ADDR.
+func orchAddress(name v1.OrchProviderRegistry) (string, error) {
+	switch name {
+	case v1.K8sOrchestrator:
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return "", fmt.Errorf("KUBERNETES_SERVICE_HOST & KUBERNETES_SERVICE_PORT must be set")
+		}
+		return net.JoinHostPort(host, port), nil
+
+	case v1.NomadOrchestrator:
+		addr := v1.GetOrchestratorAddress(map[string]string{
+			string(v1.OrchestratorNameLbl): string(name),
+		})
+		if !strings.Contains(addr, "://") {
+			addr = "http://" + addr
+		}
+		u, err := url.Parse(addr)
+		if err != nil {
+			return "", err
+		}
+		if u.Port() == "" {
+			return net.JoinHostPort(u.Hostname(), "4646"), nil
+		}
+		return u.Host, nil
+
+	default:
+		return "", fmt.Errorf("Orchestrator '%s' is not supported", name)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestStatusHealth(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/latest/status/health", nil)
+
+	out, err := s.Server.StatusSpecificRequest(resp, req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	if h := out.(*Health); h.Status != "ok" {
+		t.Fatalf("ERR: expected: ok, got: %s", h.Status)
+	}
+}
+
+func TestStatusReady(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	// Fake the kubernetes service via a local listener
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	defer ln.Close()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	os.Setenv("KUBERNETES_SERVICE_HOST", host)
+	os.Setenv("KUBERNETES_SERVICE_PORT", port)
+	defer os.Unsetenv("KUBERNETES_SERVICE_HOST")
+	defer os.Unsetenv("KUBERNETES_SERVICE_PORT")
+
+	req, _ := http.NewRequest("GET", "/latest/status/ready", nil)
+
+	out, err := s.Server.StatusSpecificRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	r := out.(*Readiness)
+	if !r.Ready || len(r.Checks) != 4 {
+		t.Fatalf("ERR: expected ready with 4 checks, got: %+v", r)
+	}
+
+	// The orchestrator is unreachable once the listener is closed
+	ln.Close()
+
+	resp := httptest.NewRecorder()
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.StatusSpecificRequest)(resp, req)
+
+	if resp.Code != 503 {
+		t.Fatalf("ERR: http resp code, expected: 503, got: %v", resp.Code)
+	}
+}
+
+func TestOrchAddress(t *testing.T) {
+	os.Setenv("NOMAD_ADDR", "http://10.0.0.1:4747")
+	defer os.Unsetenv("NOMAD_ADDR")
+
+	addr, err := orchAddress(v1.NomadOrchestrator)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	if addr != "10.0.0.1:4747" {
+		t.Fatalf("ERR: expected: 10.0.0.1:4747, got: %s", addr)
+	}
+}
+
+func TestAgentSelf(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Version = "0.3.0"
+		mc.Region = "bang"
+	})
+	defer s.Cleanup()
+
+	req, _ := http.NewRequest("GET", "/latest/agent/self", nil)
+
+	out, err := s.Server.AgentSpecificRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	self := out.(*mapiv1.AgentSelf)
+	if self.Version != "0.3.0" || self.Region != "bang" || self.Datacenter != "dc1" {
+		t.Fatalf("ERR: unexpected agent self: %+v", self)
+	}
+
+	if self.NodeName == "" || self.AdvertiseAddr == "" {
+		t.Fatalf("ERR: expected node name & advertise address: %+v", self)
+	}
+
+	req, _ = http.NewRequest("POST", "/latest/agent/self", nil)
+	if _, err := s.Server.AgentSpecificRequest(httptest.NewRecorder(), req); err == nil {
+		t.Fatalf("ERR: expected: %v", ErrInvalidMethod)
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+// storageClassParameters maps the settings of a configured storage class to
+// the labels that are set on its claims
+func storageClassParameters(conf *config.StorageClass) map[string]string {
+	params := map[string]string{}
+
+	set := func(key string, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	if conf.ReplicaCount > 0 {
+		set(string(v1.PVPReplicaCountLbl), strconv.Itoa(conf.ReplicaCount))
+	}
+	set(string(v1.PVPReplicaImageLbl), conf.ReplicaImage)
+	set(string(v1.PVPReplicaTopologyKeyLbl), conf.ReplicaTopologyKey)
+	set(string(v1.PVPControllerImageLbl), conf.ControllerImage)
+	set(string(v1.PVPPersistentPathLbl), conf.PersistentPath)
+	set(string(v1.PVPStorageSizeLbl), conf.StorageSize)
+	set(string(v1.OrchestratorNameLbl), conf.Orchestrator)
+	set(string(v1.OrchNSLbl), conf.Namespace)
+
+	return params
+}
+
+// applyStorageClass sets the parameters of the storage class that is named
+// by the claim as its labels. The labels that are set by the claim take
+// precedence.
+func (s *HTTPServer) applyStorageClass(pvc *v1.PersistentVolumeClaim) error {
+	name := mapiv1.ClaimStorageClass(pvc)
+	if name == "" {
+		return nil
+	}
+
+	sc, ok := s.maya.storageClasses[name]
+	if !ok {
+		return CodedError(422, fmt.Sprintf("Storage class '%s' not found", name))
+	}
+
+	sc.Apply(pvc)
+	return nil
+}
+
+// StorageClassesRequest is a http handler implementation. It lists the
+// configured storage classes.
+//
+// NOTE:
+//    GET /latest/storageclasses lists the storage classes while GET
+// /latest/storageclasses/<name> fetches a single storage class.
+func (s *HTTPServer) StorageClassesRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	name := strings.Trim(strings.TrimPrefix(req.URL.Path, "/latest/storageclasses"), "/")
+	if name != "" {
+		sc, ok := s.maya.storageClasses[name]
+		if !ok {
+			return nil, CodedError(404, fmt.Sprintf("Storage class '%s' not found", name))
+		}
+		return sc, nil
+	}
+
+	l := &mapiv1.StorageClassList{Items: []mapiv1.StorageClass{}}
+	l.Kind = mapiv1.StorageClassListKind
+	l.APIVersion = mapiv1.APIVersion
+
+	for _, sc := range s.maya.storageClasses {
+		l.Items = append(l.Items, *sc)
+	}
+	sort.Slice(l.Items, func(i, j int) bool {
+		return l.Items[i].Name < l.Items[j].Name
+	})
+
+	return l, nil
+}
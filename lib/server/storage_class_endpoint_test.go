@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestStorageClasses(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.StorageClasses = []*config.StorageClass{
+			{
+				Name:           "fast",
+				ReplicaCount:   3,
+				ReplicaImage:   "openebs/jiva:0.4.0",
+				PersistentPath: "/var/openebs/fast",
+				Namespace:      "storage",
+			},
+			{
+				Name:         "cheap",
+				ReplicaCount: 1,
+			},
+		}
+	})
+	defer s.Cleanup()
+
+	replicas := string(v1.PVPReplicaCountLbl)
+	image := string(v1.PVPReplicaImageLbl)
+
+	// The labels set by the claim take precedence
+	class := "fast"
+	pvc := &v1.PersistentVolumeClaim{}
+	pvc.Name = "vol1"
+	pvc.Spec.StorageClassName = &class
+	pvc.Labels = map[string]string{replicas: "2"}
+
+	if err := s.Server.admitClaim(pvc); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if pvc.Labels[replicas] != "2" {
+		t.Fatalf("ERR: expected the replica count of the claim, got: %v", pvc.Labels)
+	}
+	if pvc.Labels[image] != "openebs/jiva:0.4.0" || v1.GetOrchestratorNS(pvc.Labels) != "storage" {
+		t.Fatalf("ERR: expected the parameters of the class, got: %v", pvc.Labels)
+	}
+
+	// An unknown class
+	unknown := "slow"
+	pvc = &v1.PersistentVolumeClaim{}
+	pvc.Name = "vol2"
+	pvc.Spec.StorageClassName = &unknown
+
+	err := s.Server.admitClaim(pvc)
+	if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 422 {
+		t.Fatalf("ERR: expected a 422 coded error, got: %v", err)
+	}
+
+	// List the classes sorted by name
+	req, _ := http.NewRequest("GET", "/latest/storageclasses", nil)
+	obj, err := s.Server.StorageClassesRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	l := obj.(*mapiv1.StorageClassList)
+	if l.Kind != mapiv1.StorageClassListKind || len(l.Items) != 2 {
+		t.Fatalf("ERR: unexpected list: %#v", l)
+	}
+	if l.Items[0].Name != "cheap" || l.Items[1].Name != "fast" {
+		t.Fatalf("ERR: expected the classes sorted by name, got: %s, %s", l.Items[0].Name, l.Items[1].Name)
+	}
+	if l.Items[0].Parameters[replicas] != "1" {
+		t.Fatalf("ERR: unexpected parameters: %v", l.Items[0].Parameters)
+	}
+
+	// Fetch a single class
+	req, _ = http.NewRequest("GET", "/latest/storageclasses/fast", nil)
+	obj, err = s.Server.StorageClassesRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if sc := obj.(*mapiv1.StorageClass); sc.Parameters[string(v1.PVPPersistentPathLbl)] != "/var/openebs/fast" {
+		t.Fatalf("ERR: unexpected parameters: %v", sc.Parameters)
+	}
+
+	cases := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{"GET", "/latest/storageclasses/slow", 404},
+		{"POST", "/latest/storageclasses", 405},
+		{"DELETE", "/latest/storageclasses/fast", 405},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.path, nil)
+		_, err := s.Server.StorageClassesRequest(httptest.NewRecorder(), req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != c.code {
+			t.Fatalf("ERR: %s %s expected %d, got: %v", c.method, c.path, c.code, err)
+		}
+	}
+}
+
+func TestSetupStorageClasses_Invalid(t *testing.T) {
+	ms := &MayaApiServer{config: config.DefaultMayaConfig()}
+	ms.config.StorageClasses = []*config.StorageClass{
+		{Name: "fast", Orchestrator: "swarm"},
+	}
+
+	if err := ms.setupStorageClasses(); err == nil {
+		t.Fatalf("ERR: expected an error for an unknown orchestrator")
+	}
+}
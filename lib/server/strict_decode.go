@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// CheckUnknownYamlFields returns an error if the YAML document has a field
+// that is not known to the type of out. It is exported for the cli to decode
+// the files it sends the same way the server does.
+//
+// NOTE:
+//    The YAML document is decoded to JSON by ghodss/yaml against the type of
+// out. Hence the unknown fields are looked up against the json tags of the
+// type, the same way encoding/json does.
+func CheckUnknownYamlFields(b []byte, out interface{}) error {
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return err
+	}
+
+	return checkUnknownJsonFields(j, out)
+}
+
+// checkUnknownJsonFields returns an error if the JSON document has a field
+// that is not known to the type of out
+func checkUnknownJsonFields(b []byte, out interface{}) error {
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+
+	var unknown []string
+	collectUnknownFields(reflect.TypeOf(out), doc, "", &unknown)
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown field(s) %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// collectUnknownFields walks the decoded document along with the type it is
+// decoded into & collects the path of every field that is not known
+func collectUnknownFields(t reflect.Type, doc interface{}, path string, unknown *[]string) {
+	for t.Kind() == reflect.Ptr {
+		if t.Implements(jsonUnmarshalerType) {
+			return
+		}
+		t = t.Elem()
+	}
+
+	// Types that decode themselves are not looked into
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		fields := map[string]reflect.Type{}
+		jsonFields(t, fields)
+
+		for k, v := range obj {
+			ft, ok := fields[strings.ToLower(k)]
+			if !ok {
+				*unknown = append(*unknown, fmt.Sprintf("'%s%s'", path, k))
+				continue
+			}
+			collectUnknownFields(ft, v, path+k+".", unknown)
+		}
+
+	case reflect.Map:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for k, v := range obj {
+			collectUnknownFields(t.Elem(), v, path+k+".", unknown)
+		}
+
+	case reflect.Slice, reflect.Array:
+		arr, ok := doc.([]interface{})
+		if !ok {
+			return
+		}
+		for i, v := range arr {
+			collectUnknownFields(t.Elem(), v, fmt.Sprintf("%s%d.", path, i), unknown)
+		}
+	}
+}
+
+// jsonFields collects the lower cased json names of the fields of the struct
+// type. The fields of the embedded structs are collected as well.
+func jsonFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			jsonFields(ft, fields)
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+}
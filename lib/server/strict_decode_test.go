@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+// makeStrictTestServer returns a test server that decodes the request bodies
+// strictly
+func makeStrictTestServer(t *testing.T) *TestServer {
+	return makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.StrictDecoding = true
+	})
+}
+
+func TestDecodeBody(t *testing.T) {
+	s := makeStrictTestServer(t)
+	defer s.Cleanup()
+
+	cases := []struct {
+		cType string
+		body  string
+		code  int
+	}{
+		{"", `{"metadata": {"name": "vol1"}}`, 0},
+		{"application/json", `{"metadata": {"name": "vol1", "lables": {}}}`, 400},
+		{"application/yaml", "metadata:\n  name: vol1\n  labels:\n    volumeprovisioner.mapi.openebs.io/replica-count: 1\n", 0},
+		{"application/yaml", "kind: PersistentVolumeClaim\nmetadata:\n  name: vol1\n  lables:\n    app: mysql\n", 400},
+		{"application/x-yaml", "spec:\n  accessModes: [ReadWriteOnce]\n  resources:\n    requests:\n      storage: 1G\n", 0},
+		{"text/plain", `{"metadata": {"name": "vol1"}}`, 415},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(c.body))
+		if c.cType != "" {
+			req.Header.Set("Content-Type", c.cType)
+		}
+
+		pvc := v1.PersistentVolumeClaim{}
+		err := s.Server.decodeBody(req, &pvc)
+		if c.code == 0 {
+			if err != nil {
+				t.Fatalf("ERR: %s: %v", c.body, err)
+			}
+			continue
+		}
+
+		if err == nil || err.(HTTPCodedError).Code() != c.code {
+			t.Fatalf("ERR: %s: expected: %d, got: %v", c.body, c.code, err)
+		}
+	}
+}
+
+func TestDecodeBodyUnknownYamlField(t *testing.T) {
+	s := makeStrictTestServer(t)
+	defer s.Cleanup()
+
+	body := "metadata:\n  name: vol1\n  lables:\n    app: mysql\n"
+	req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/yaml")
+
+	err := s.Server.decodeBody(req, &v1.PersistentVolumeClaim{})
+	if err == nil || !strings.Contains(err.Error(), "'metadata.lables'") {
+		t.Fatalf("ERR: expected the unknown field, got: %v", err)
+	}
+}
+
+func TestDecodeBodyNotStrict(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	// Unknown fields are ignored unless strict decoding is enabled
+	bodies := map[string]string{
+		"application/json": `{"metadata": {"name": "vol1", "lables": {}}}`,
+		"application/yaml": "metadata:\n  name: vol1\n  lables:\n    app: mysql\n",
+	}
+
+	for cType, body := range bodies {
+		req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", cType)
+
+		pvc := v1.PersistentVolumeClaim{}
+		if err := s.Server.decodeBody(req, &pvc); err != nil {
+			t.Fatalf("ERR: %s: %v", cType, err)
+		}
+
+		if pvc.Name != "vol1" {
+			t.Fatalf("ERR: %s: expected name: vol1, got: %s", cType, pvc.Name)
+		}
+	}
+}
+
+func TestMaxRequestBodySize(t *testing.T) {
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.MaxRequestBodySize = 64
+	})
+	defer s.Cleanup()
+
+	body := `{"metadata": {"name": "vol1", "labels": {"app": "` + strings.Repeat("a", 64) + `"}}}`
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(body))
+
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.VSMSpecificRequest)(resp, req)
+
+	if resp.Code != 413 {
+		t.Fatalf("ERR: http resp code, expected: 413, got: %v", resp.Code)
+	}
+
+	// A body of exactly the limit is read in full
+	body = `{"metadata": {"name": "` + strings.Repeat("a", 64-26) + `"}}`
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(body))
+
+	s.Server.limitBody(resp, req)
+
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil || len(b) != 64 {
+		t.Fatalf("ERR: expected 64 bytes, got: %d: %v", len(b), err)
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+// TestingT is the part of testing.TB the test server needs. It keeps the
+// testing package, & its flags, out of the binaries that link this package.
+type TestingT interface {
+	Fatalf(format string, args ...interface{})
+}
+
+// TestServer is an in-process maya api server that serves HTTP on a free
+// local port. It is used by the tests of this package as well as by the
+// tests of the packages that talk to maya api server e.g. its client.
+type TestServer struct {
+	T      TestingT
+	Dir    string
+	Maya   *MayaApiServer
+	Server *HTTPServer
+
+	// HTTPAddr is the address the server is reachable at e.g.
+	// http://127.0.0.1:5656
+	HTTPAddr string
+}
+
+// NewTestServer starts a test server. The config is customized by fnmc if it
+// is not nil. The server must be stopped via Cleanup.
+func NewTestServer(t TestingT, fnmc func(mc *config.MayaConfig)) *TestServer {
+	return makeHTTPTestServerWithWriter(t, nil, fnmc)
+}
+
+// Cleanup stops the test server & removes its data dir
+func (s *TestServer) Cleanup() {
+	s.Server.Shutdown()
+	s.Maya.Shutdown()
+	os.RemoveAll(s.Dir)
+}
+
+// makeHTTPTestServerWithWriter returns a test server whose logs will be written to
+// the passed writer. If the writer is nil, the logs are written to stderr.
+func makeHTTPTestServerWithWriter(t TestingT, w io.Writer, fnmc func(mc *config.MayaConfig)) *TestServer {
+	dir, maya := makeMayaServer(t, fnmc)
+	if w == nil {
+		w = maya.logOutput
+	}
+	srv, err := NewHTTPServer(maya, maya.config, w)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	s := &TestServer{
+		T:        t,
+		Dir:      dir,
+		Maya:     maya,
+		Server:   srv,
+		HTTPAddr: "http://" + srv.addr,
+	}
+	return s
+}
+
+func getPort() int {
+	addr, err := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	l, err := net.ListenTCP("tcp", addr)
+	if err != nil {
+		panic(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func tmpDir(t TestingT) string {
+	dir, err := ioutil.TempDir("", "mapiserver")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return dir
+}
+
+func makeMayaServer(t TestingT, fnmc func(*config.MayaConfig)) (string, *MayaApiServer) {
+	dir := tmpDir(t)
+
+	// Customize the server configuration
+	conf := config.DefaultMayaConfig()
+
+	// Set the data_dir
+	conf.DataDir = dir
+
+	// Bind and set ports
+	conf.BindAddr = "127.0.0.1"
+	conf.Ports = &config.Ports{
+		HTTP: getPort(),
+	}
+	conf.NodeName = fmt.Sprintf("Node %d", conf.Ports.HTTP)
+
+	if fnmc != nil {
+		fnmc(conf)
+	}
+
+	if err := conf.NormalizeAddrs(); err != nil {
+		t.Fatalf("error normalizing config: %v", err)
+	}
+
+	maya, err := NewMayaApiServer(conf, os.Stderr)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("err: %v", err)
+	}
+	return dir, maya
+}
@@ -40,6 +40,9 @@ func (s *HTTPServer) vsmSpecificGetRequest(resp http.ResponseWriter, req *http.R
 
 	switch {
 
+	case strings.HasSuffix(path, "/events"):
+		vsmName := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/events")
+		return s.vsmEvents(resp, req, vsmName)
 	case strings.Contains(path, "/info/"):
 		vsmName := strings.TrimPrefix(path, "/info/")
 		return s.vsmRead(resp, req, vsmName)
@@ -58,6 +61,31 @@ func (s *HTTPServer) vsmList(resp http.ResponseWriter, req *http.Request) (inter
 
 	fmt.Println("[DEBUG] Processing VSM list request")
 
+	health, err := parseHealthFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// ?region=* lists the VSMs of every region
+	if req.URL.Query().Get("region") == AllRegions {
+		return s.vsmFederatedList(req, health), nil
+	}
+
+	l, err := listVSMs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set the health of each VSM & filter them if requested
+	l = filterVSMsByHealth(l, health)
+
+	fmt.Println("[DEBUG] Processed VSM list request successfully")
+
+	return l, nil
+}
+
+// listVSMs fetches the VSMs via the default persistent volume provisioner
+func listVSMs() (*v1.PersistentVolumeList, error) {
 	// Create a PVC
 	pvc := &v1.PersistentVolumeClaim{}
 
@@ -82,14 +110,7 @@ func (s *HTTPServer) vsmList(resp http.ResponseWriter, req *http.Request) (inter
 		return nil, fmt.Errorf("VSM list is not supported by '%s:%s'", pvp.Label(), pvp.Name())
 	}
 
-	l, err := lister.List()
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Println("[DEBUG] Processed VSM list request successfully")
-
-	return l, nil
+	return lister.List()
 }
 
 // vsmRead is the http handler that fetches the details of a VSM
@@ -101,6 +122,22 @@ func (s *HTTPServer) vsmRead(resp http.ResponseWriter, req *http.Request, vsmNam
 		return nil, CodedError(400, fmt.Sprintf("VSM name is missing"))
 	}
 
+	details, err := readVSM(vsmName)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Header().Set("ETag", vsmETag(details))
+	setVSMHealth(details)
+
+	fmt.Println("[DEBUG] Processed VSM read request successfully for '" + vsmName + "'")
+
+	return details, nil
+}
+
+// readVSM fetches the details of a VSM via the default persistent volume
+// provisioner
+func readVSM(vsmName string) (*v1.PersistentVolume, error) {
 	// Create a PVC
 	pvc := &v1.PersistentVolumeClaim{}
 	pvc.Name = vsmName
@@ -133,8 +170,6 @@ func (s *HTTPServer) vsmRead(resp http.ResponseWriter, req *http.Request, vsmNam
 		return nil, CodedError(404, fmt.Sprintf("VSM '%s' not found", vsmName))
 	}
 
-	fmt.Println("[DEBUG] Processed VSM read request successfully for '" + vsmName + "'")
-
 	return details, nil
 }
 
@@ -147,6 +182,26 @@ func (s *HTTPServer) vsmDelete(resp http.ResponseWriter, req *http.Request, vsmN
 		return nil, CodedError(400, fmt.Sprintf("VSM name is missing"))
 	}
 
+	s.vsmLock.Lock()
+	defer s.vsmLock.Unlock()
+
+	if err := checkIfMatch(req, vsmName); err != nil {
+		return nil, err
+	}
+
+	err := deleteVSM(vsmName)
+	s.maya.vsmDeleted(vsmName, err)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("[DEBUG] Processed VSM delete request successfully for '" + vsmName + "'")
+
+	return fmt.Sprintf("VSM '%s' deleted successfully", vsmName), nil
+}
+
+// deleteVSM deletes a VSM via the default persistent volume provisioner
+func deleteVSM(vsmName string) error {
 	// Create a PVC
 	pvc := &v1.PersistentVolumeClaim{}
 	pvc.Name = vsmName
@@ -154,37 +209,35 @@ func (s *HTTPServer) vsmDelete(resp http.ResponseWriter, req *http.Request, vsmN
 	// Get the persistent volume provisioner instance
 	pvp, err := provisioner.GetVolumeProvisioner(pvc.Labels)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Set the volume provisioner profile to provisioner
 	_, err = pvp.Profile(pvc)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	remover, ok, err := pvp.Remover()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if !ok {
-		return nil, fmt.Errorf("VSM delete is not supported by '%s:%s'", pvp.Label(), pvp.Name())
+		return fmt.Errorf("VSM delete is not supported by '%s:%s'", pvp.Label(), pvp.Name())
 	}
 
 	removed, err := remover.Remove()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// If there was not any err & still no removal
 	if !removed {
-		return nil, CodedError(404, fmt.Sprintf("VSM '%s' not found", vsmName))
+		return CodedError(404, fmt.Sprintf("VSM '%s' not found", vsmName))
 	}
 
-	fmt.Println("[DEBUG] Processed VSM delete request successfully for '" + vsmName + "'")
-
-	return fmt.Sprintf("VSM '%s' deleted successfully", vsmName), nil
+	return nil
 }
 
 // vsmAdd is the http handler that fetches the details of a VSM
@@ -195,8 +248,8 @@ func (s *HTTPServer) vsmAdd(resp http.ResponseWriter, req *http.Request) (interf
 	pvc := v1.PersistentVolumeClaim{}
 
 	// The yaml/json spec is decoded to pvc struct
-	if err := decodeBody(req, &pvc); err != nil {
-		return nil, CodedError(400, err.Error())
+	if err := s.decodeBody(req, &pvc); err != nil {
+		return nil, err
 	}
 
 	// Name is expected to be available even in the minimalist specs
@@ -204,6 +257,32 @@ func (s *HTTPServer) vsmAdd(resp http.ResponseWriter, req *http.Request) (interf
 		return nil, CodedError(400, fmt.Sprintf("VSM name missing in '%v'", pvc))
 	}
 
+	// The admission policies & the validation run before the orchestrator
+	// is invoked
+	if err := s.admitClaim(&pvc); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.admitVSMAdd(req, &pvc)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	details, err := addVSM(&pvc)
+	s.maya.vsmAdded(pvc.Name, err)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("[DEBUG] Processed VSM add request successfully for '" + pvc.Name + "'")
+
+	return details, nil
+}
+
+// addVSM creates a VSM via the persistent volume provisioner that is
+// selected by the labels of the claim
+func addVSM(pvc *v1.PersistentVolumeClaim) (*v1.PersistentVolume, error) {
 	// Get persistent volume provisioner instance
 	pvp, err := provisioner.GetVolumeProvisioner(pvc.Labels)
 	if err != nil {
@@ -211,7 +290,7 @@ func (s *HTTPServer) vsmAdd(resp http.ResponseWriter, req *http.Request) (interf
 	}
 
 	// Set the volume provisioner profile to provisioner
-	_, err = pvp.Profile(&pvc)
+	_, err = pvp.Profile(pvc)
 	if err != nil {
 		return nil, err
 	}
@@ -223,12 +302,10 @@ func (s *HTTPServer) vsmAdd(resp http.ResponseWriter, req *http.Request) (interf
 
 	// TODO
 	// pvc should not be passed again !!
-	details, err := adder.Add(&pvc)
+	details, err := adder.Add(pvc)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("[DEBUG] Processed VSM add request successfully for '" + pvc.Name + "'")
-
 	return details, nil
 }
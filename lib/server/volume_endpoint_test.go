@@ -1 +1,48 @@
 package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInvalidVSMAdd(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	body := `
+kind: PersistentVolumeClaim
+apiVersion: v1
+metadata:
+  name: My-VSM
+  labels:
+    volumeprovisioner.mapi.openebs.io/replica-count: abc
+    volumeprovisioner.mapi.openebs.io/storage-size: 10 gigs
+`
+
+	handlers := map[string]func(http.ResponseWriter, *http.Request) (interface{}, error){
+		"/latest/volumes/": s.Server.VSMSpecificRequest,
+		"/latest/vsms/":    s.Server.TypedVSMRequest,
+	}
+
+	for path, handler := range handlers {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/yaml")
+
+		s.Server.wrap(RequestCounter, RequestDuration, handler)(resp, req)
+
+		if resp.Code != 422 {
+			t.Fatalf("ERR: %s: http resp code, expected: 422, got: %v", path, resp.Code)
+		}
+
+		// Every violation is reported
+		for _, v := range []string{"name:", "replica-count:", "storage-size:"} {
+			if !strings.Contains(resp.Body.String(), v) {
+				t.Fatalf("ERR: %s: expected violation %s, got: %s", path, v, resp.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/openebs/maya/types/v1"
+)
+
+// vsmETag returns the entity tag of the VSM. It is derived from the
+// resourceVersion of the persistent volume. The content of the persistent
+// volume is hashed if the orchestrator does not provide a resourceVersion.
+func vsmETag(pv *v1.PersistentVolume) string {
+	if pv.ResourceVersion != "" {
+		return strconv.Quote(pv.ResourceVersion)
+	}
+
+	b, err := json.Marshal(pv)
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum(b)
+
+	return strconv.Quote(hex.EncodeToString(sum[:8]))
+}
+
+// etagMatches returns true if the entity tag matches one of the tags of an
+// If-Match or If-None-Match header. As per RFC 7232, If-Match uses the strong
+// comparison in which weak tags never match, while If-None-Match uses the
+// weak comparison in which weak tags are compared by their value.
+func etagMatches(header, etag string, strong bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+
+		// A weak tag never matches in the strong comparison
+		if strings.HasPrefix(tag, "W/") {
+			if strong {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch verifies the If-Match header of a request that mutates an
+// existing VSM. A 412 is returned if the VSM has been modified since the
+// client read it.
+func checkIfMatch(req *http.Request, vsmName string) error {
+	match := req.Header.Get("If-Match")
+	if match == "" {
+		return nil
+	}
+
+	pv, err := readVSM(vsmName)
+	if err != nil {
+		return err
+	}
+
+	if etag := vsmETag(pv); !etagMatches(match, etag, true) {
+		return CodedError(412, fmt.Sprintf("VSM '%s' has been modified, its ETag is %s", vsmName, etag))
+	}
+	return nil
+}
+
+// checkIfNoneMatch verifies the If-None-Match header of a request that
+// creates a VSM. If-None-Match: * gives create only semantics i.e. a 412 is
+// returned if the VSM exists already.
+func checkIfNoneMatch(req *http.Request, vsmName string) error {
+	match := req.Header.Get("If-None-Match")
+	if match == "" {
+		return nil
+	}
+
+	pv, err := readVSM(vsmName)
+	if err != nil {
+		if coded, ok := err.(HTTPCodedError); ok && coded.Code() == 404 {
+			return nil
+		}
+		return err
+	}
+
+	if etagMatches(match, vsmETag(pv), false) {
+		return CodedError(412, fmt.Sprintf("VSM '%s' already exists", vsmName))
+	}
+	return nil
+}
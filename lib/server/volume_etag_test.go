@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+)
+
+func TestVSMETag(t *testing.T) {
+	pv := &v1.PersistentVolume{}
+	pv.Name = "vol1"
+	pv.ResourceVersion = "42"
+
+	if etag := vsmETag(pv); etag != `"42"` {
+		t.Fatalf("ERR: expected the resourceVersion, got: %s", etag)
+	}
+
+	// The content is hashed if there is no resourceVersion
+	pv.ResourceVersion = ""
+	etag := vsmETag(pv)
+	if etag == "" || etag != vsmETag(pv) {
+		t.Fatalf("ERR: expected a stable etag, got: %s", etag)
+	}
+
+	pv.Annotations = map[string]string{"vsm.openebs.io/replica-count": "3"}
+	if vsmETag(pv) == etag {
+		t.Fatalf("ERR: expected the etag to change with the content")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	cases := []struct {
+		header   string
+		strong   bool
+		expected bool
+	}{
+		{`"42"`, true, true},
+		{`"42"`, false, true},
+		{`W/"42"`, true, false},
+		{`W/"42"`, false, true},
+		{`W/"42", "42"`, true, true},
+		{`"41", "42"`, true, true},
+		{`*`, true, true},
+		{`"41"`, false, false},
+		{`42`, false, false},
+	}
+
+	for _, c := range cases {
+		if actual := etagMatches(c.header, `"42"`, c.strong); actual != c.expected {
+			t.Fatalf("ERR: %s: strong: %v: expected: %v, got: %v", c.header, c.strong, c.expected, actual)
+		}
+	}
+}
+
+func TestNoPrecondition(t *testing.T) {
+	req, _ := http.NewRequest("DELETE", "/latest/vsms/vol1", nil)
+
+	// The VSM is not read if there is no precondition
+	if err := checkIfMatch(req, "vol1"); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if err := checkIfNoneMatch(req, "vol1"); err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+}
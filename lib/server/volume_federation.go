@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+const (
+	// RegionAPILbl is the annotation set against a persistent volume of a
+	// federated listing with the region of the VSM
+	RegionAPILbl = "vsm.openebs.io/region"
+
+	// DatacenterAPILbl is the annotation set against a persistent volume of a
+	// federated listing with the datacenter of the VSM
+	DatacenterAPILbl = "vsm.openebs.io/datacenter"
+)
+
+// FederatedVSMList is the collection of VSMs of all the regions. The regions
+// that could not be listed are reported as warnings.
+type FederatedVSMList struct {
+	Items []v1.PersistentVolume `json:"items"`
+
+	// Warnings has an entry for every region that could not be listed
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// regionVSMs is the outcome of listing the VSMs of a single region
+type regionVSMs struct {
+	region     string
+	datacenter string
+	items      []v1.PersistentVolume
+	err        error
+}
+
+// vsmFederatedList lists the VSMs of this region & of every peer region
+// concurrently. The results are merged in the order of the configured peers
+// with the VSMs of this region first.
+func (s *HTTPServer) vsmFederatedList(req *http.Request, health mapiv1.VSMHealth) *FederatedVSMList {
+	peers := s.maya.config.Peers
+	results := make([]regionVSMs, len(peers)+1)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0] = s.localRegionVSMs(health)
+	}()
+
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer *config.Peer) {
+			defer wg.Done()
+			results[i+1] = s.peerRegionVSMs(req, peer, health)
+		}(i, peer)
+	}
+
+	wg.Wait()
+
+	l := &FederatedVSMList{Items: []v1.PersistentVolume{}}
+	for _, r := range results {
+		if r.err != nil {
+			l.Warnings = append(l.Warnings, fmt.Sprintf("Region '%s' is unavailable: %v", r.region, r.err))
+			continue
+		}
+
+		for _, pv := range r.items {
+			if pv.Annotations == nil {
+				pv.Annotations = map[string]string{}
+			}
+			pv.Annotations[RegionAPILbl] = r.region
+			pv.Annotations[DatacenterAPILbl] = r.datacenter
+
+			l.Items = append(l.Items, pv)
+		}
+	}
+
+	return l
+}
+
+// localRegionVSMs lists the VSMs of this region
+func (s *HTTPServer) localRegionVSMs(health mapiv1.VSMHealth) regionVSMs {
+	r := regionVSMs{
+		region:     s.maya.config.Region,
+		datacenter: s.maya.config.Datacenter,
+	}
+
+	l, err := listVSMs()
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	if l = filterVSMsByHealth(l, health); l != nil {
+		r.items = l.Items
+	}
+
+	return r
+}
+
+// peerRegionVSMs lists the VSMs of the peer's region. The request is sent
+// to the v1 path with the peer's region so that the peer lists only its own
+// VSMs. The v1 VSMs carry no health, hence the health is set & filtered on
+// here. All the other query params are passed as is.
+func (s *HTTPServer) peerRegionVSMs(req *http.Request, peer *config.Peer, health mapiv1.VSMHealth) regionVSMs {
+	r := regionVSMs{
+		region:     peer.Region,
+		datacenter: peer.Datacenter,
+	}
+
+	u := *req.URL
+	u.Path = "/" + APIVersionV1 + "/volumes/"
+	q := u.Query()
+	q.Set("region", peer.Region)
+	q.Del("health")
+	u.RawQuery = q.Encode()
+
+	out := *req
+	out.URL = &u
+	out.RequestURI = ""
+
+	presp, err := s.forwardToPeer(peer, &out, nil)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	defer presp.Body.Close()
+
+	body, err := ioutil.ReadAll(presp.Body)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	if presp.StatusCode != 200 {
+		r.err = fmt.Errorf("%d %s", presp.StatusCode, strings.TrimSpace(string(body)))
+		return r
+	}
+
+	var l v1.PersistentVolumeList
+	if err := json.Unmarshal(body, &l); err != nil {
+		r.err = err
+		return r
+	}
+
+	r.items = filterVSMsByHealth(&l, health).Items
+	return r
+}
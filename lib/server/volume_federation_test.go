@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openebs/mayaserver/lib/config"
+)
+
+func TestVSMFederatedList(t *testing.T) {
+	var path, query string
+	west := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		fmt.Fprint(w, `{"items": [{"metadata": {"name": "vol1"}}, {"metadata": {"name": "vol2"}}]}`)
+	}))
+	defer west.Close()
+
+	east := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no orchestrator", 500)
+	}))
+	defer east.Close()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Peers = []*config.Peer{
+			{Region: "west", Datacenter: "dc2", Addresses: []string{west.URL}},
+			{Region: "east", Addresses: []string{east.URL}},
+			{Region: "north", Addresses: []string{"127.0.0.1:1"}},
+		}
+	})
+	defer s.Cleanup()
+
+	req, _ := http.NewRequest("GET", "/latest/volumes/?region=*&health=healthy", nil)
+	l := s.Server.vsmFederatedList(req, "")
+
+	if path != "/v1/volumes/" || query != "region=west" {
+		t.Fatalf("ERR: expected the v1 path with the peer's region, got: %s?%s", path, query)
+	}
+
+	if len(l.Items) != 2 {
+		t.Fatalf("ERR: expected 2 VSMs, got: %+v", l.Items)
+	}
+
+	for _, pv := range l.Items {
+		if pv.Annotations[RegionAPILbl] != "west" || pv.Annotations[DatacenterAPILbl] != "dc2" {
+			t.Fatalf("ERR: expected west & dc2 annotations, got: %v", pv.Annotations)
+		}
+	}
+
+	// the local region has no orchestrator in the tests
+	warned := map[string]bool{}
+	for _, w := range l.Warnings {
+		for _, region := range []string{"global", "east", "north"} {
+			if strings.Contains(w, "'"+region+"'") {
+				warned[region] = true
+			}
+		}
+	}
+
+	if len(l.Warnings) != 3 || len(warned) != 3 {
+		t.Fatalf("ERR: expected warnings for global, east & north, got: %v", l.Warnings)
+	}
+}
+
+func TestTypedVSMFederatedList(t *testing.T) {
+	west := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items": [{"metadata": {"name": "vol1"}}]}`)
+	}))
+	defer west.Close()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.Peers = []*config.Peer{
+			{Region: "west", Datacenter: "dc2", Addresses: []string{west.URL}},
+		}
+	})
+	defer s.Cleanup()
+
+	req, _ := http.NewRequest("GET", "/latest/vsms/?region=*", nil)
+	l, err := s.Server.typedVSMList(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	if len(l.Items) != 1 || l.Items[0].Name != "vol1" || l.Items[0].Annotations[RegionAPILbl] != "west" {
+		t.Fatalf("ERR: expected vol1 of west, got: %+v", l.Items)
+	}
+
+	// the local region has no orchestrator in the tests
+	if len(l.Warnings) != 1 || !strings.Contains(l.Warnings[0], "'global'") {
+		t.Fatalf("ERR: expected a warning for global, got: %v", l.Warnings)
+	}
+
+	// A federated list can not block
+	req, _ = http.NewRequest("GET", "/latest/vsms/?region=*&index=1", nil)
+	if _, err := s.Server.typedVSMList(httptest.NewRecorder(), req); err == nil || err.(HTTPCodedError).Code() != 400 {
+		t.Fatalf("ERR: expected 400, got: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+// setVSMHealth computes the overall health of the VSM & sets it along with
+// its reason as annotations of the persistent volume
+func setVSMHealth(pv *v1.PersistentVolume) mapiv1.VSMHealth {
+	if pv == nil {
+		return ""
+	}
+
+	health, reason := mapiv1.FromPersistentVolume(pv).Health()
+
+	if pv.Annotations == nil {
+		pv.Annotations = map[string]string{}
+	}
+	pv.Annotations[mapiv1.HealthAPILbl] = string(health)
+	pv.Annotations[mapiv1.HealthReasonAPILbl] = reason
+
+	return health
+}
+
+// parseHealthFilter parses the ?health query param. An empty health is
+// returned if the param is not set.
+func parseHealthFilter(req *http.Request) (mapiv1.VSMHealth, error) {
+	value := req.URL.Query().Get("health")
+	if value == "" {
+		return "", nil
+	}
+
+	health, err := mapiv1.ParseHealth(value)
+	if err != nil {
+		return "", CodedError(400, err.Error())
+	}
+
+	return health, nil
+}
+
+// filterVSMsByHealth sets the health of every VSM & retains only the ones
+// that match the given health. All the VSMs are retained if health is empty.
+func filterVSMsByHealth(pvl *v1.PersistentVolumeList, health mapiv1.VSMHealth) *v1.PersistentVolumeList {
+	if pvl == nil {
+		return nil
+	}
+
+	items := []v1.PersistentVolume{}
+	for i := range pvl.Items {
+		pv := pvl.Items[i]
+		if h := setVSMHealth(&pv); health == "" || h == health {
+			items = append(items, pv)
+		}
+	}
+	pvl.Items = items
+
+	return pvl
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+func TestFilterVSMsByHealth(t *testing.T) {
+	healthy := v1.PersistentVolume{}
+	healthy.Name = "healthy"
+	healthy.Annotations = map[string]string{
+		string(v1.ControllerStatusAPILbl): "Running",
+		string(v1.ReplicaStatusAPILbl):    "Running,Running",
+		string(v1.ReplicaCountAPILbl):     "2",
+	}
+
+	degraded := v1.PersistentVolume{}
+	degraded.Name = "degraded"
+	degraded.Annotations = map[string]string{
+		string(v1.ControllerStatusAPILbl): "Running",
+		string(v1.ReplicaStatusAPILbl):    "Running,Pending",
+		string(v1.ReplicaCountAPILbl):     "2",
+	}
+
+	pvl := &v1.PersistentVolumeList{Items: []v1.PersistentVolume{healthy, degraded}}
+
+	l := filterVSMsByHealth(pvl, mapiv1.Degraded)
+	if len(l.Items) != 1 || l.Items[0].Name != "degraded" {
+		t.Fatalf("expected only the degraded VSM, got: %v", l.Items)
+	}
+
+	if reason := l.Items[0].Annotations[mapiv1.HealthReasonAPILbl]; reason == "" {
+		t.Fatalf("expected health reason to be set")
+	}
+
+	pvl = &v1.PersistentVolumeList{Items: []v1.PersistentVolume{healthy, degraded}}
+	if l := filterVSMsByHealth(pvl, ""); len(l.Items) != 2 {
+		t.Fatalf("expected all the VSMs, got: %d", len(l.Items))
+	}
+}
+
+func TestParseHealthFilter(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/latest/volumes/?health=Offline", nil)
+	if h, err := parseHealthFilter(req); err != nil || h != mapiv1.Offline {
+		t.Fatalf("expected: %s, got: %s, err: %v", mapiv1.Offline, h, err)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/volumes/?health=bad", nil)
+	_, err := parseHealthFilter(req)
+	if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 400 {
+		t.Fatalf("expected a 400 coded error, got: %v", err)
+	}
+}
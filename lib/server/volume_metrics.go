@@ -0,0 +1,187 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// volumeMetricsInterval is the interval at which the VSMs are listed to
+// refresh the per volume gauges
+const volumeMetricsInterval = 30 * time.Second
+
+var (
+	// These descriptors are served from the last listed VSMs. Each of them
+	// is labelled by the volume's name & its namespace.
+
+	// mayaVolumeCapacityBytes exposes the provisioned capacity of a volume
+	mayaVolumeCapacityBytes = prometheus.NewDesc(
+		"maya_volume_capacity_bytes",
+		"Provisioned capacity of the volume in bytes.",
+		[]string{"volume", "namespace"}, nil,
+	)
+	// mayaVolumeReplicasDesired exposes the replica count requested for a
+	// volume
+	mayaVolumeReplicasDesired = prometheus.NewDesc(
+		"maya_volume_replicas_desired",
+		"Number of replicas desired for the volume.",
+		[]string{"volume", "namespace"}, nil,
+	)
+	// mayaVolumeReplicasReady exposes the count of replicas of a volume that
+	// are running
+	mayaVolumeReplicasReady = prometheus.NewDesc(
+		"maya_volume_replicas_ready",
+		"Number of replicas of the volume that are running.",
+		[]string{"volume", "namespace"}, nil,
+	)
+	// mayaVolumeControllerStatus exposes the count of controllers of a volume
+	// against each of the controller statuses
+	mayaVolumeControllerStatus = prometheus.NewDesc(
+		"maya_volume_controller_status",
+		"Number of controllers of the volume in a given status.",
+		[]string{"volume", "namespace", "status"}, nil,
+	)
+	// mayaVolumePhase is set to 1 against the current phase of a volume
+	mayaVolumePhase = prometheus.NewDesc(
+		"maya_volume_phase",
+		"Current PersistentVolumePhase of the volume.",
+		[]string{"volume", "namespace", "phase"}, nil,
+	)
+)
+
+// volumeMetrics is the collector of the per volume metrics
+var volumeMetrics = &volumeCollector{}
+
+// init registers the per volume collector
+func init() {
+	prometheus.MustRegister(volumeMetrics)
+}
+
+// volumeSample is a VSM as of the last listing along with its namespace
+type volumeSample struct {
+	vsm       *mapiv1.VSM
+	namespace string
+}
+
+// volumeCollector is a prometheus collector that serves the per volume
+// metrics from the VSMs of the last listing.
+//
+// NOTE:
+//    The snapshot is swapped as a whole. Hence a scrape either sees the
+// previous or the current listing but never a partial one.
+type volumeCollector struct {
+	lock    sync.RWMutex
+	samples []volumeSample
+}
+
+// Describe implements prometheus.Collector
+func (c *volumeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- mayaVolumeCapacityBytes
+	ch <- mayaVolumeReplicasDesired
+	ch <- mayaVolumeReplicasReady
+	ch <- mayaVolumeControllerStatus
+	ch <- mayaVolumePhase
+}
+
+// Collect implements prometheus.Collector
+func (c *volumeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.RLock()
+	samples := c.samples
+	c.lock.RUnlock()
+
+	for _, sample := range samples {
+		vsm := sample.vsm
+		name, ns := vsm.Name, sample.namespace
+
+		if vsm.Spec.Capacity != "" {
+			ch <- prometheus.MustNewConstMetric(mayaVolumeCapacityBytes, prometheus.GaugeValue, float64(vsm.Spec.CapacityBytes), name, ns)
+		}
+
+		if vsm.Spec.ReplicaCount > 0 {
+			ch <- prometheus.MustNewConstMetric(mayaVolumeReplicasDesired, prometheus.GaugeValue, float64(vsm.Spec.ReplicaCount), name, ns)
+		}
+
+		ch <- prometheus.MustNewConstMetric(mayaVolumeReplicasReady, prometheus.GaugeValue, float64(vsm.ReadyReplicas()), name, ns)
+
+		// Sorted for a deterministic order of the series
+		counts := map[string]int{}
+		for _, ctrl := range vsm.Status.Controllers {
+			counts[ctrl.Status]++
+		}
+		statuses := make([]string, 0, len(counts))
+		for status := range counts {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			ch <- prometheus.MustNewConstMetric(mayaVolumeControllerStatus, prometheus.GaugeValue, float64(counts[status]), name, ns, status)
+		}
+
+		phase := string(vsm.Status.Phase)
+		if phase == "" {
+			phase = "Unknown"
+		}
+		ch <- prometheus.MustNewConstMetric(mayaVolumePhase, prometheus.GaugeValue, 1, name, ns, phase)
+	}
+}
+
+// update replaces the snapshot with the provided VSMs. Volumes that are no
+// longer listed are not exported anymore.
+func (c *volumeCollector) update(pvl *v1.PersistentVolumeList) {
+	var samples []volumeSample
+	if pvl != nil {
+		for i := range pvl.Items {
+			pv := &pvl.Items[i]
+			samples = append(samples, volumeSample{
+				vsm:       mapiv1.FromPersistentVolume(pv),
+				namespace: vsmNamespace(pv),
+			})
+		}
+	}
+
+	c.lock.Lock()
+	c.samples = samples
+	c.lock.Unlock()
+}
+
+// collectVolumeMetrics lists the VSMs at every interval & refreshes the per
+// volume gauges. It runs till maya api server is shutdown.
+func (ms *MayaApiServer) collectVolumeMetrics(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pvl, err := listVSMs()
+		if err != nil {
+			ms.logger.Printf("[WARN] maya api server: failed to list VSMs for metrics: %v", err)
+		} else {
+			volumeMetrics.update(pvl)
+			ms.notifyVolumeChanges(pvl)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ms.shutdownCh:
+			return
+		}
+	}
+}
+
+// vsmNamespace returns the namespace of the VSM. It falls back to the
+// orchestrator's namespace label & then to the default namespace.
+func vsmNamespace(pv *v1.PersistentVolume) string {
+	if pv.Namespace != "" {
+		return pv.Namespace
+	}
+
+	if ns := pv.Labels[string(v1.OrchNSLbl)]; ns != "" {
+		return ns
+	}
+
+	return v1.DefaultOrchestratorNS()
+}
+
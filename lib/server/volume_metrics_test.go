@@ -0,0 +1,73 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// collectValues collects the metrics of the collector keyed by their
+// descriptor name & their label values e.g. maya_volume_phase{vol1,default,Unknown}
+func collectValues(t *testing.T, c prometheus.Collector) map[string]float64 {
+	ch := make(chan prometheus.Metric, 64)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	values := map[string]float64{}
+	for metric := range ch {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		desc := metric.Desc().String()
+		name := desc[strings.Index(desc, `fqName: "`)+9:]
+		name = name[:strings.Index(name, `"`)]
+
+		lvs := []string{}
+		for _, lp := range m.GetLabel() {
+			lvs = append(lvs, lp.GetValue())
+		}
+		values[name+"{"+strings.Join(lvs, ",")+"}"] = m.GetGauge().GetValue()
+	}
+	return values
+}
+
+func TestVolumeCollector(t *testing.T) {
+	pv := v1.PersistentVolume{}
+	pv.Name = "vol1"
+	pv.Annotations = map[string]string{
+		string(v1.VolumeSizeAPILbl):       "1G",
+		string(v1.ReplicaCountAPILbl):     "3",
+		string(v1.ReplicaStatusAPILbl):    "Running,Pending,Running",
+		string(v1.ControllerStatusAPILbl): "Running",
+	}
+
+	c := &volumeCollector{}
+	c.update(&v1.PersistentVolumeList{Items: []v1.PersistentVolume{pv}})
+
+	values := collectValues(t, c)
+	expected := map[string]float64{
+		"maya_volume_capacity_bytes{default,vol1}":            1e9,
+		"maya_volume_replicas_desired{default,vol1}":          3,
+		"maya_volume_replicas_ready{default,vol1}":            2,
+		"maya_volume_controller_status{default,Running,vol1}": 1,
+		"maya_volume_phase{default,Unknown,vol1}":             1,
+	}
+	for k, v := range expected {
+		if actual, ok := values[k]; !ok || actual != v {
+			t.Fatalf("expected %s: %v, got: %v in %v", k, v, actual, values)
+		}
+	}
+
+	// A volume that is no longer listed is not exported
+	c.update(&v1.PersistentVolumeList{})
+	if values := collectValues(t, c); len(values) != 0 {
+		t.Fatalf("expected no metrics, got: %v", values)
+	}
+}
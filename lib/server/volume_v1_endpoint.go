@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openebs/maya/types/v1"
+)
+
+// V1VSMSpecificRequest is a http handler implementation. It serves
+// /v1/volumes with the shapes & the status codes these had when the API was
+// versioned.
+//
+// NOTE:
+//
+//	The VSMs are not annotated with their health, ETags are neither set nor
+//
+// honored & a body that is neither YAML nor JSON is decoded as JSON. The
+// claims still pass through the storage classes, the admission policies &
+// the quotas, but a rejected claim is a 400 instead of a 422.
+func (s *HTTPServer) V1VSMSpecificRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+
+	fmt.Println("[DEBUG] Processing v1", req.Method, "request")
+
+	req.Header.Del("If-Match")
+	req.Header.Del("If-None-Match")
+
+	switch req.Method {
+	case "PUT", "POST":
+		return s.v1VSMAdd(resp, req)
+	case "GET":
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/"+APIVersionV1+"/volumes")
+
+	switch {
+	case strings.Contains(path, "/info/"):
+		return s.v1VSMRead(resp, req, strings.TrimPrefix(path, "/info/"))
+	case strings.Contains(path, "/delete/"):
+		return s.v1VSMDelete(resp, req, strings.TrimPrefix(path, "/delete/"))
+	case path == "/":
+		return s.v1VSMList(resp, req)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+// v1VSMList lists the VSMs of this region. The VSMs of every region are
+// listed at /latest or /v2 only.
+func (s *HTTPServer) v1VSMList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.URL.Query().Get("region") == AllRegions {
+		return nil, CodedError(400, "?region=* is not supported by /v1")
+	}
+
+	return listVSMs()
+}
+
+// v1VSMRead fetches the details of a VSM
+func (s *HTTPServer) v1VSMRead(resp http.ResponseWriter, req *http.Request, vsmName string) (interface{}, error) {
+	if vsmName == "" {
+		return nil, CodedError(400, fmt.Sprintf("VSM name is missing"))
+	}
+
+	return readVSM(vsmName)
+}
+
+// v1VSMDelete deletes a VSM
+func (s *HTTPServer) v1VSMDelete(resp http.ResponseWriter, req *http.Request, vsmName string) (interface{}, error) {
+	if vsmName == "" {
+		return nil, CodedError(400, fmt.Sprintf("VSM name is missing"))
+	}
+
+	s.vsmLock.Lock()
+	defer s.vsmLock.Unlock()
+
+	err := deleteVSM(vsmName)
+	s.maya.vsmDeleted(vsmName, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("VSM '%s' deleted successfully", vsmName), nil
+}
+
+// v1VSMAdd creates a VSM out of a leniently decoded claim
+func (s *HTTPServer) v1VSMAdd(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	pvc := v1.PersistentVolumeClaim{}
+
+	if err := v1DecodeBody(req, &pvc); err != nil {
+		return nil, err
+	}
+
+	if pvc.Name == "" {
+		return nil, CodedError(400, fmt.Sprintf("VSM name missing in '%v'", pvc))
+	}
+
+	if err := s.admitClaim(&pvc); err != nil {
+		return nil, v1Error(err)
+	}
+
+	unlock, err := s.admitVSMAdd(req, &pvc)
+	if err != nil {
+		return nil, v1Error(err)
+	}
+	defer unlock()
+
+	details, err := addVSM(&pvc)
+	s.maya.vsmAdded(pvc.Name, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// v1DecodeBody decodes a YAML body as YAML & any other body as JSON. The
+// unknown fields are ignored.
+func v1DecodeBody(req *http.Request, out interface{}) error {
+	cType, err := getContentType(req)
+	if err != nil {
+		return CodedError(400, err.Error())
+	}
+
+	if strings.Contains(cType, "yaml") {
+		err = decodeYamlBody(req, out, false)
+	} else {
+		err = decodeJsonBody(req, out, false)
+	}
+
+	if err != nil {
+		return bodyError(err)
+	}
+	return nil
+}
+
+// v1Error maps the 422 of an invalid claim to the 400 that /v1 answers a bad
+// request with
+func v1Error(err error) error {
+	if coded, ok := err.(HTTPCodedError); ok && coded.Code() == 422 {
+		return CodedError(400, err.Error())
+	}
+	return err
+}
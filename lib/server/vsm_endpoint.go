@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+// TypedVSMRequest is a http handler implementation. It deals with HTTP
+// requests w.r.t typed VSM resources.
+//
+// NOTE:
+//    GET /latest/vsms/ lists the VSMs while GET /latest/vsms/<name> fetches
+// a single VSM. POST /latest/vsms/ creates a VSM while DELETE
+// /latest/vsms/<name> deletes it. GET /latest/vsms/<name>/events lists the
+// events of a VSM. The GETs of the VSMs are blocking queries if ?index is
+// set.
+func (s *HTTPServer) TypedVSMRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	vsmName := strings.TrimPrefix(req.URL.Path, "/latest/vsms/")
+
+	if req.Method == "GET" && strings.HasSuffix(vsmName, "/events") {
+		return s.vsmEvents(resp, req, strings.TrimSuffix(vsmName, "/events"))
+	}
+
+	// Is req valid ?
+	if vsmName == req.URL.Path || strings.Contains(vsmName, "/") {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	switch {
+	case req.Method == "GET" && vsmName == "":
+		return s.typedVSMList(resp, req)
+	case req.Method == "GET":
+		return s.typedVSMRead(resp, req, vsmName)
+	case (req.Method == "PUT" || req.Method == "POST") && vsmName == "":
+		return s.typedVSMAdd(resp, req)
+	case req.Method == "DELETE" && vsmName != "":
+		return s.typedVSMDelete(resp, req, vsmName)
+	default:
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+}
+
+// typedVSMList is the http handler that lists VSMs as typed resources
+func (s *HTTPServer) typedVSMList(resp http.ResponseWriter, req *http.Request) (*mapiv1.VSMList, error) {
+
+	fmt.Println("[DEBUG] Processing typed VSM list request")
+
+	health, err := parseHealthFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// ?region=* lists the VSMs of every region. The index of this region
+	// does not track the peers, hence such a query can not block.
+	if req.URL.Query().Get("region") == AllRegions {
+		if req.URL.Query().Get("index") != "" {
+			return nil, CodedError(400, "?index is not supported along with ?region=*")
+		}
+
+		fl := s.vsmFederatedList(req, health)
+		l := mapiv1.FromPersistentVolumeList(&v1.PersistentVolumeList{Items: fl.Items})
+		l.Warnings = fl.Warnings
+		return l, nil
+	}
+
+	if err := s.blockOnVSMIndex(resp, req); err != nil {
+		return nil, err
+	}
+
+	pvl, err := listVSMs()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapiv1.FromPersistentVolumeList(filterVSMsByHealth(pvl, health)), nil
+}
+
+// typedVSMRead is the http handler that fetches a VSM as a typed resource
+func (s *HTTPServer) typedVSMRead(resp http.ResponseWriter, req *http.Request, vsmName string) (*mapiv1.VSM, error) {
+
+	fmt.Println("[DEBUG] Processing typed VSM read request")
+
+	if err := s.blockOnVSMIndex(resp, req); err != nil {
+		return nil, err
+	}
+
+	pv, err := readVSM(vsmName)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Header().Set("ETag", vsmETag(pv))
+	return mapiv1.FromPersistentVolume(pv), nil
+}
+
+// typedVSMAdd is the http handler that creates a VSM & returns it as a typed
+// resource
+func (s *HTTPServer) typedVSMAdd(resp http.ResponseWriter, req *http.Request) (*mapiv1.VSM, error) {
+
+	fmt.Println("[DEBUG] Processing typed VSM add request")
+
+	pvc := v1.PersistentVolumeClaim{}
+
+	// The yaml/json spec is decoded to pvc struct
+	if err := s.decodeBody(req, &pvc); err != nil {
+		return nil, err
+	}
+
+	if pvc.Name == "" {
+		return nil, CodedError(400, "VSM name is missing")
+	}
+
+	// The admission policies & the validation run before the orchestrator
+	// is invoked
+	if err := s.admitClaim(&pvc); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.admitVSMAdd(req, &pvc)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	pv, err := addVSM(&pvc)
+	s.maya.vsmAdded(pvc.Name, err)
+	if err != nil {
+		return nil, err
+	}
+
+	setVSMHealth(pv)
+
+	return mapiv1.FromPersistentVolume(pv), nil
+}
+
+// typedVSMDelete is the http handler that deletes a VSM. Nothing is returned
+// on success.
+func (s *HTTPServer) typedVSMDelete(resp http.ResponseWriter, req *http.Request, vsmName string) (interface{}, error) {
+
+	fmt.Println("[DEBUG] Processing typed VSM delete request")
+
+	s.vsmLock.Lock()
+	defer s.vsmLock.Unlock()
+
+	if err := checkIfMatch(req, vsmName); err != nil {
+		return nil, err
+	}
+
+	err := deleteVSM(vsmName)
+	s.maya.vsmDeleted(vsmName, err)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.WriteHeader(204)
+	return nil, nil
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+)
+
+// typedTestVSM is a VSM as reported by the provisioner i.e. with its details
+// set as annotations
+func typedTestVSM() v1.PersistentVolume {
+	pv := v1.PersistentVolume{}
+	pv.Name = "vol1"
+	pv.Annotations = map[string]string{
+		string(v1.VolumeSizeAPILbl):       "1G",
+		string(v1.ReplicaCountAPILbl):     "2",
+		string(v1.ReplicaIPsAPILbl):       "10.0.0.2,10.0.0.3",
+		string(v1.ReplicaStatusAPILbl):    "Running,Pending",
+		string(v1.ControllerIPsAPILbl):    "10.0.0.1",
+		string(v1.ControllerStatusAPILbl): "Running",
+		string(v1.IQNAPILbl):              "iqn.2016-09.com.openebs.jiva:vol1",
+		"custom":                          "value",
+	}
+	return pv
+}
+
+// checkTypedTestVSM verifies the conversion of typedTestVSM
+func checkTypedTestVSM(t *testing.T, vsm *mapiv1.VSM) {
+	if vsm.Kind != mapiv1.VSMKind || vsm.APIVersion != mapiv1.APIVersion {
+		t.Fatalf("ERR: expected kind: %s/%s, got: %s/%s", mapiv1.APIVersion, mapiv1.VSMKind, vsm.APIVersion, vsm.Kind)
+	}
+	if vsm.Name != "vol1" {
+		t.Fatalf("ERR: expected name: vol1, got: %s", vsm.Name)
+	}
+	if vsm.Spec.Capacity != "1G" || vsm.Spec.CapacityBytes != 1000000000 {
+		t.Fatalf("ERR: expected capacity: 1G/1000000000, got: %s/%d", vsm.Spec.Capacity, vsm.Spec.CapacityBytes)
+	}
+	if vsm.Spec.ReplicaCount != 2 {
+		t.Fatalf("ERR: expected replica count: 2, got: %d", vsm.Spec.ReplicaCount)
+	}
+
+	reps := vsm.Status.Replicas
+	if len(reps) != 2 || reps[0] != (mapiv1.Replica{IP: "10.0.0.2", Status: "Running"}) ||
+		reps[1] != (mapiv1.Replica{IP: "10.0.0.3", Status: "Pending"}) {
+		t.Fatalf("ERR: unexpected replicas: %+v", reps)
+	}
+	if vsm.ReadyReplicas() != 1 {
+		t.Fatalf("ERR: expected ready replicas: 1, got: %d", vsm.ReadyReplicas())
+	}
+
+	ctrls := vsm.Status.Controllers
+	if len(ctrls) != 1 || ctrls[0] != (mapiv1.Controller{IP: "10.0.0.1", Status: "Running"}) {
+		t.Fatalf("ERR: unexpected controllers: %+v", ctrls)
+	}
+	if vsm.Status.Target.IQN != "iqn.2016-09.com.openebs.jiva:vol1" {
+		t.Fatalf("ERR: unexpected target iqn: %s", vsm.Status.Target.IQN)
+	}
+
+	// Only the annotations that are not converted are carried over
+	if len(vsm.Annotations) != 1 || vsm.Annotations["custom"] != "value" {
+		t.Fatalf("ERR: unexpected annotations: %v", vsm.Annotations)
+	}
+}
+
+func TestInvalidMethodTypedVSM(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/latest/vsms/vol1", nil)
+
+	out, err := s.Server.TypedVSMRequest(resp, req)
+	if err == nil || err.Error() != ErrInvalidMethod {
+		t.Fatalf("ERR: expected: %v, got: %v", ErrInvalidMethod, err)
+	}
+
+	if out != nil {
+		t.Fatalf("Service must not return any value, for invalid request")
+	}
+}
+
+func TestInvalidPathTypedVSM(t *testing.T) {
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/latest/vsms/vol1/extra", nil)
+
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.TypedVSMRequest)(resp, req)
+
+	if resp.Code != 405 {
+		t.Fatalf("err http resp code, expected: 405, got: %v", resp.Code)
+	}
+}
+
+func TestListTypedVSM(t *testing.T) {
+	defer useFakeVolumes(typedTestVSM())()
+
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/latest/vsms/", nil)
+
+	out, err := s.Server.TypedVSMRequest(resp, req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	l, ok := out.(*mapiv1.VSMList)
+	if !ok {
+		t.Fatalf("ERR: expected: *v1.VSMList, got: %T", out)
+	}
+	if l.Kind != mapiv1.VSMListKind {
+		t.Fatalf("ERR: expected kind: %s, got: %s", mapiv1.VSMListKind, l.Kind)
+	}
+	if len(l.Items) != 1 {
+		t.Fatalf("ERR: expected 1 VSM, got: %d", len(l.Items))
+	}
+	checkTypedTestVSM(t, &l.Items[0])
+}
+
+func TestReadTypedVSM(t *testing.T) {
+	defer useFakeVolumes(typedTestVSM())()
+
+	s := makeHTTPTestServer(t, nil)
+	defer s.Cleanup()
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/latest/vsms/vol1", nil)
+
+	out, err := s.Server.TypedVSMRequest(resp, req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+
+	vsm, ok := out.(*mapiv1.VSM)
+	if !ok {
+		t.Fatalf("ERR: expected: *v1.VSM, got: %T", out)
+	}
+	checkTypedTestVSM(t, vsm)
+
+	if resp.Header().Get("ETag") == "" {
+		t.Fatalf("ERR: expected an ETag for the VSM")
+	}
+
+	// A VSM that does not exist is not found
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/latest/vsms/vol2", nil)
+
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.TypedVSMRequest)(resp, req)
+
+	if resp.Code != 404 {
+		t.Fatalf("ERR: http resp code, expected: 404, got: %v", resp.Code)
+	}
+}
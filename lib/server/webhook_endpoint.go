@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openebs/maya/types/v1"
+	mapiv1 "github.com/openebs/mayaserver/lib/api/v1"
+	"github.com/openebs/mayaserver/lib/event"
+	"github.com/openebs/mayaserver/lib/webhook"
+)
+
+// volumeState is the health & capacity of a VSM as of the last metrics
+// collection
+type volumeState struct {
+	health   mapiv1.VSMHealth
+	size     string
+	capacity int64
+}
+
+// notify publishes a volume lifecycle event to the configured webhooks. It
+// is a no-op if no webhook is configured.
+func (ms *MayaApiServer) notify(t webhook.EventType, volume string, details map[string]string) {
+	if ms.webhooks == nil {
+		return
+	}
+
+	ms.webhooks.Publish(webhook.Event{
+		Type:       t,
+		Region:     ms.config.Region,
+		Datacenter: ms.config.Datacenter,
+		Volume:     volume,
+		Details:    details,
+	})
+}
+
+// notifyVolumeChanges records & publishes the VSMs that turned degraded or
+// whose capacity changed since the last metrics collection.
+//
+// NOTE:
+//    A VSM is only compared once it was seen by a previous collection. Hence
+// nothing is published for the VSMs listed right after a restart.
+func (ms *MayaApiServer) notifyVolumeChanges(pvl *v1.PersistentVolumeList) {
+	states := map[string]volumeState{}
+	if pvl != nil {
+		for i := range pvl.Items {
+			vsm := mapiv1.FromPersistentVolume(&pvl.Items[i])
+			health, reason := vsm.Health()
+			cur := volumeState{health: health, size: vsm.Spec.Capacity, capacity: vsm.Spec.CapacityBytes}
+			states[vsm.Name] = cur
+
+			prev, ok := ms.volumeStates[vsm.Name]
+			if !ok {
+				continue
+			}
+
+			if cur.health == mapiv1.Degraded && prev.health != mapiv1.Degraded {
+				ms.events.Record(event.Warning, "Degraded", vsm.Name, reason)
+				ms.notify(webhook.VolumeDegraded, vsm.Name, map[string]string{
+					"previousHealth": string(prev.health),
+					"reason":         reason,
+				})
+			}
+
+			if cur.capacity != prev.capacity && cur.capacity > 0 && prev.capacity > 0 {
+				ms.events.Recordf(event.Normal, "Resized", vsm.Name, "VSM is resized from %s to %s", prev.size, cur.size)
+				ms.notify(webhook.VolumeResized, vsm.Name, map[string]string{
+					"previousCapacityBytes": fmt.Sprintf("%d", prev.capacity),
+					"capacityBytes":         fmt.Sprintf("%d", cur.capacity),
+				})
+			}
+		}
+	}
+
+	if volumeStatesChanged(ms.volumeStates, states) {
+		ms.vsmIndex.bump()
+	}
+	ms.volumeStates = states
+}
+
+// volumeStatesChanged returns true if a VSM has appeared, disappeared or
+// changed between the two observations
+func volumeStatesChanged(prev, cur map[string]volumeState) bool {
+	if len(prev) != len(cur) {
+		return true
+	}
+	for name, st := range cur {
+		if p, ok := prev[name]; !ok || p != st {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhooksRequest is a http handler implementation. It serves the delivery
+// status of the configured webhooks.
+//
+// NOTE:
+//    GET /latest/webhooks lists every webhook with a summary of its
+// deliveries while GET /latest/webhooks/<name> lists the deliveries of a
+// single webhook.
+func (s *HTTPServer) WebhooksRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if err := s.checkACL(req); err != nil {
+		return nil, err
+	}
+
+	d := s.maya.webhooks
+	name := strings.Trim(strings.TrimPrefix(req.URL.Path, "/latest/webhooks"), "/")
+
+	if name == "" {
+		if d == nil {
+			return []webhook.HookStatus{}, nil
+		}
+		return d.Status(), nil
+	}
+
+	if d == nil || s.maya.config.Webhook(name) == nil {
+		return nil, CodedError(404, fmt.Sprintf("Webhook '%s' not found", name))
+	}
+
+	return d.Deliveries(name), nil
+}
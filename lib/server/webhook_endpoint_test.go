@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openebs/maya/types/v1"
+	"github.com/openebs/mayaserver/lib/config"
+	"github.com/openebs/mayaserver/lib/webhook"
+)
+
+// makeWebhookReceiver returns a server that sends the received events to
+// the returned channel
+func makeWebhookReceiver(t *testing.T) (*httptest.Server, chan webhook.Event) {
+	events := make(chan webhook.Event, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !webhook.Verify("h00k", body, r.Header.Get(webhook.SignatureHeader)) {
+			w.WriteHeader(401)
+			return
+		}
+
+		var e webhook.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("ERR: %v", err)
+		}
+		events <- e
+	}))
+	return srv, events
+}
+
+func expectEvent(t *testing.T, events chan webhook.Event, typ webhook.EventType, volume string) webhook.Event {
+	select {
+	case e := <-events:
+		if e.Type != typ || e.Volume != volume {
+			t.Fatalf("ERR: expected %s of %s, got: %+v", typ, volume, e)
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ERR: timed out waiting for %s of %s", typ, volume)
+	}
+	return webhook.Event{}
+}
+
+func TestWebhooksRequest(t *testing.T) {
+	recv, events := makeWebhookReceiver(t)
+	defer recv.Close()
+
+	s := makeHTTPTestServer(t, func(mc *config.MayaConfig) {
+		mc.ACL = &config.ACL{Enabled: true, ManagementToken: "s3cr3t"}
+		mc.Webhooks = []*config.Webhook{
+			{Name: "cmdb", URL: recv.URL, Secret: "h00k"},
+		}
+	})
+	defer s.Cleanup()
+
+	// There is no orchestrator to provision the VSM
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/latest/volumes/", bytes.NewBufferString(`{"metadata": {"name": "vol1"}}`))
+	s.Server.wrap(RequestCounter, RequestDuration, s.Server.VSMSpecificRequest)(resp, req)
+
+	if resp.Code < 500 {
+		t.Fatalf("ERR: http resp code, expected a failure, got: %v", resp.Code)
+	}
+
+	e := expectEvent(t, events, webhook.VolumeCreateFailed, "vol1")
+	if e.Region != "global" || e.Details["error"] == "" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+
+	// The status requires the management token
+	req, _ = http.NewRequest("GET", "/latest/webhooks", nil)
+	if _, err := s.Server.WebhooksRequest(httptest.NewRecorder(), req); err == nil || err.Error() != ErrPermissionDenied {
+		t.Fatalf("ERR: expected: %v, got: %v", ErrPermissionDenied, err)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/webhooks", nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	out, err := s.Server.WebhooksRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if st := out.([]webhook.HookStatus); len(st) != 1 || st[0].Name != "cmdb" || st[0].Last == nil {
+		t.Fatalf("ERR: unexpected status: %+v", st)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/webhooks/cmdb", nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	out, err = s.Server.WebhooksRequest(httptest.NewRecorder(), req)
+	if err != nil {
+		t.Fatalf("ERR: %v", err)
+	}
+	if dls := out.([]*webhook.Delivery); len(dls) != 1 || dls[0].Event.Type != webhook.VolumeCreateFailed {
+		t.Fatalf("ERR: unexpected deliveries: %+v", dls)
+	}
+
+	req, _ = http.NewRequest("GET", "/latest/webhooks/unknown", nil)
+	req.Header.Set(MayaTokenHeader, "s3cr3t")
+	_, err = s.Server.WebhooksRequest(httptest.NewRecorder(), req)
+	if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 404 {
+		t.Fatalf("ERR: expected a 404 coded error, got: %v", err)
+	}
+}
+
+func TestNotifyVolumeChanges(t *testing.T) {
+	recv, events := makeWebhookReceiver(t)
+	defer recv.Close()
+
+	dir, maya := makeMayaServer(t, func(mc *config.MayaConfig) {
+		mc.Webhooks = []*config.Webhook{
+			{Name: "cmdb", URL: recv.URL, Secret: "h00k"},
+		}
+	})
+	defer os.RemoveAll(dir)
+	defer maya.Shutdown()
+
+	makePV := func(replicas, size string) v1.PersistentVolume {
+		pv := v1.PersistentVolume{}
+		pv.Name = "vol1"
+		pv.Annotations = map[string]string{
+			string(v1.ControllerStatusAPILbl): "Running",
+			string(v1.ReplicaStatusAPILbl):    replicas,
+			string(v1.ReplicaCountAPILbl):     "2",
+			string(v1.VolumeSizeAPILbl):       size,
+		}
+		return pv
+	}
+
+	// Nothing is published for a VSM that is seen for the first time
+	maya.notifyVolumeChanges(&v1.PersistentVolumeList{Items: []v1.PersistentVolume{makePV("Running,Pending", "1G")}})
+	maya.notifyVolumeChanges(&v1.PersistentVolumeList{Items: []v1.PersistentVolume{makePV("Running,Running", "1G")}})
+	maya.notifyVolumeChanges(&v1.PersistentVolumeList{Items: []v1.PersistentVolume{makePV("Running,Pending", "2G")}})
+
+	expectEvent(t, events, webhook.VolumeDegraded, "vol1")
+	e := expectEvent(t, events, webhook.VolumeResized, "vol1")
+	if e.Details["previousCapacityBytes"] != "1000000000" || e.Details["capacityBytes"] != "2000000000" {
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	}
+
+	select {
+	case e := <-events:
+		t.Fatalf("ERR: unexpected event: %+v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,580 @@
+// Package webhook notifies HTTP endpoints of the volume lifecycle events.
+// Every notification is queued as a delivery that is persisted on disk &
+// retried with exponential backoff till it succeeds or runs out of attempts.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType is the type of a volume lifecycle event
+type EventType string
+
+const (
+	// VolumeCreated is published once a VSM is created
+	VolumeCreated EventType = "volume.created"
+
+	// VolumeDeleted is published once a VSM is deleted
+	VolumeDeleted EventType = "volume.deleted"
+
+	// VolumeCreateFailed is published if a VSM could not be created
+	VolumeCreateFailed EventType = "volume.create_failed"
+
+	// VolumeDegraded is published when a VSM turns degraded
+	VolumeDegraded EventType = "volume.degraded"
+
+	// VolumeResized is published when the capacity of a VSM changes
+	VolumeResized EventType = "volume.resized"
+)
+
+// EventTypes are all the event types a webhook can subscribe to
+var EventTypes = []EventType{
+	VolumeCreated,
+	VolumeDeleted,
+	VolumeCreateFailed,
+	VolumeDegraded,
+	VolumeResized,
+}
+
+const (
+	// EventHeader carries the type of the event of a delivery
+	EventHeader = "X-Maya-Event"
+
+	// DeliveryHeader carries the id of a delivery. It is the same across
+	// the attempts of the delivery.
+	DeliveryHeader = "X-Maya-Delivery"
+
+	// SignatureHeader carries the HMAC-SHA256 of the payload keyed by the
+	// secret of the webhook e.g. sha256=<hex>
+	SignatureHeader = "X-Maya-Signature"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts after which a delivery
+	// is failed
+	DefaultMaxAttempts = 8
+
+	// DefaultBackoff is the wait before the first retry. It is doubled for
+	// every further retry.
+	DefaultBackoff = 5 * time.Second
+
+	// DefaultMaxBackoff caps the wait between retries
+	DefaultMaxBackoff = 10 * time.Minute
+
+	// retainFinished is the number of delivered or failed deliveries that
+	// are retained for the status
+	retainFinished = 100
+)
+
+// Hook is a HTTP endpoint that is notified of the events it subscribes to
+type Hook struct {
+	Name string
+	URL  string
+
+	// Events are the subscribed event types. Every event is subscribed if
+	// there are none.
+	Events []EventType
+
+	// Secret signs the payloads if set
+	Secret string
+}
+
+// subscribes returns true if the hook is notified of the event type
+func (h *Hook) subscribes(t EventType) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the payload of a delivery
+type Event struct {
+	ID         string            `json:"id"`
+	Type       EventType         `json:"type"`
+	Time       time.Time         `json:"time"`
+	Region     string            `json:"region,omitempty"`
+	Datacenter string            `json:"datacenter,omitempty"`
+	Volume     string            `json:"volume"`
+	Details    map[string]string `json:"details,omitempty"`
+}
+
+// Delivery is the notification of an event to a single hook
+type Delivery struct {
+	ID          string     `json:"id"`
+	Hook        string     `json:"hook"`
+	Event       Event      `json:"event"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	NextAttempt time.Time  `json:"nextAttempt,omitempty"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// HookStatus summarizes the deliveries of a hook
+type HookStatus struct {
+	Name      string      `json:"name"`
+	URL       string      `json:"url"`
+	Events    []EventType `json:"events"`
+	Pending   int         `json:"pending"`
+	Delivered int         `json:"delivered"`
+	Failed    int         `json:"failed"`
+	Last      *Delivery   `json:"last,omitempty"`
+}
+
+// Dispatcher queues the events as deliveries & delivers them in the
+// background. Every hook has its own worker so that a slow hook does not
+// hold up the deliveries to the others.
+type Dispatcher struct {
+	// MaxAttempts, Backoff & MaxBackoff control the retries. They are set
+	// to their defaults by NewDispatcher.
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+
+	// Client sends the deliveries
+	Client *http.Client
+
+	dir    string
+	hooks  []*Hook
+	logger *log.Logger
+
+	lock       sync.Mutex
+	deliveries map[string]*Delivery
+
+	// wakeChs wake the worker of a hook once a delivery is queued for it
+	wakeChs map[string]chan struct{}
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher whose queue is persisted in the given
+// directory. The deliveries that are pending in the directory are resumed
+// once the dispatcher is started.
+func NewDispatcher(dir string, hooks []*Hook, logger *log.Logger) (*Dispatcher, error) {
+	for _, h := range hooks {
+		if h.URL == "" {
+			return nil, fmt.Errorf("webhook '%s' has no url", h.Name)
+		}
+		for _, e := range h.Events {
+			if !validEventType(e) {
+				return nil, fmt.Errorf("webhook '%s' has an invalid event '%s'", h.Name, e)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create webhook dir: %v", err)
+	}
+
+	d := &Dispatcher{
+		MaxAttempts: DefaultMaxAttempts,
+		Backoff:     DefaultBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		dir:         dir,
+		hooks:       hooks,
+		logger:      logger,
+		deliveries:  map[string]*Delivery{},
+		wakeChs:     map[string]chan struct{}{},
+		stopCh:      make(chan struct{}),
+	}
+	for _, h := range hooks {
+		d.wakeChs[h.Name] = make(chan struct{}, 1)
+	}
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// validEventType returns true if the event type is known
+func validEventType(t EventType) bool {
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the persisted deliveries. A pending delivery of a hook that is
+// not configured anymore is failed as there is no worker to deliver it.
+func (d *Dispatcher) load() error {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+
+		dl := &Delivery{}
+		if err := json.Unmarshal(b, dl); err != nil {
+			d.logger.Printf("[WARN] webhook: Skipping corrupt delivery %s: %v", f, err)
+			continue
+		}
+
+		if dl.Status == StatusPending && d.hook(dl.Hook) == nil {
+			dl.Status = StatusFailed
+			dl.LastError = fmt.Sprintf("webhook '%s' is not configured", dl.Hook)
+			if err := d.persist(dl); err != nil {
+				d.logger.Printf("[ERR] webhook: Failed to persist delivery %s: %v", dl.ID, err)
+			}
+		}
+
+		d.deliveries[dl.ID] = dl
+	}
+	return nil
+}
+
+// persist writes the delivery to disk. The file is replaced atomically.
+func (d *Dispatcher) persist(dl *Delivery) error {
+	b, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(d.dir, dl.ID+".json")
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Start delivers the queued events in the background till Stop is called
+func (d *Dispatcher) Start() {
+	for _, h := range d.hooks {
+		d.workers.Add(1)
+		go d.run(h.Name)
+	}
+}
+
+// Stop stops the deliveries & waits for the workers to exit. The pending
+// deliveries remain persisted. It is safe to call Stop more than once.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+	d.workers.Wait()
+}
+
+// Publish queues a delivery of the event for every hook that subscribes to
+// it
+func (d *Dispatcher) Publish(e Event) {
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	var woken []chan struct{}
+
+	d.lock.Lock()
+	for _, h := range d.hooks {
+		if !h.subscribes(e.Type) {
+			continue
+		}
+		woken = append(woken, d.wakeChs[h.Name])
+
+		dl := &Delivery{
+			ID:          newID(),
+			Hook:        h.Name,
+			Event:       e,
+			Status:      StatusPending,
+			CreatedAt:   time.Now().UTC(),
+			NextAttempt: time.Now().UTC(),
+		}
+		d.deliveries[dl.ID] = dl
+
+		if err := d.persist(dl); err != nil {
+			d.logger.Printf("[ERR] webhook: Failed to persist delivery %s: %v", dl.ID, err)
+		}
+	}
+	d.lock.Unlock()
+
+	for _, wakeCh := range woken {
+		select {
+		case wakeCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run is the worker of a hook. It delivers the due deliveries of the hook &
+// then waits till the next one is due or a new one is published.
+func (d *Dispatcher) run(hook string) {
+	defer d.workers.Done()
+
+	for {
+		wait := d.deliverDue(hook)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-d.wakeChs[hook]:
+			timer.Stop()
+		case <-d.stopCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// deliverDue attempts every pending delivery of the hook that is due. The
+// wait till the next pending delivery of the hook is due is returned.
+func (d *Dispatcher) deliverDue(hook string) time.Duration {
+	now := time.Now().UTC()
+
+	d.lock.Lock()
+	var due []*Delivery
+	for _, dl := range d.deliveries {
+		if dl.Hook == hook && dl.Status == StatusPending && !dl.NextAttempt.After(now) {
+			copied := *dl
+			due = append(due, &copied)
+		}
+	}
+	d.lock.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Before(due[j].CreatedAt) })
+
+	for _, dl := range due {
+		select {
+		case <-d.stopCh:
+			return time.Hour
+		default:
+		}
+
+		d.attempt(dl)
+
+		d.lock.Lock()
+		d.deliveries[dl.ID] = dl
+		if err := d.persist(dl); err != nil {
+			d.logger.Printf("[ERR] webhook: Failed to persist delivery %s: %v", dl.ID, err)
+		}
+		d.lock.Unlock()
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.prune()
+
+	wait := time.Hour
+	for _, dl := range d.deliveries {
+		if dl.Hook != hook || dl.Status != StatusPending {
+			continue
+		}
+		if w := dl.NextAttempt.Sub(time.Now()); w < wait {
+			wait = w
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// attempt sends the delivery once & updates its status
+func (d *Dispatcher) attempt(dl *Delivery) {
+	dl.Attempts++
+
+	err := d.send(dl)
+	if err == nil {
+		now := time.Now().UTC()
+		dl.Status = StatusDelivered
+		dl.DeliveredAt = &now
+		dl.LastError = ""
+		return
+	}
+
+	dl.LastError = err.Error()
+	if dl.Attempts >= d.MaxAttempts {
+		dl.Status = StatusFailed
+		d.logger.Printf("[ERR] webhook: Delivery %s to '%s' failed after %d attempts: %v", dl.ID, dl.Hook, dl.Attempts, err)
+		return
+	}
+
+	dl.NextAttempt = time.Now().UTC().Add(d.backoff(dl.Attempts))
+	d.logger.Printf("[WARN] webhook: Delivery %s to '%s' failed, retrying at %s: %v", dl.ID, dl.Hook, dl.NextAttempt.Format(time.RFC3339), err)
+}
+
+// backoff returns the wait after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.Backoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// send posts the event to the hook of the delivery
+func (d *Dispatcher) send(dl *Delivery) error {
+	h := d.hook(dl.Hook)
+	if h == nil {
+		return fmt.Errorf("webhook '%s' is not configured", dl.Hook)
+	}
+
+	body, err := json.Marshal(dl.Event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(dl.Event.Type))
+	req.Header.Set(DeliveryHeader, dl.ID)
+	if h.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(h.Secret, body))
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return nil
+}
+
+// hook returns the hook of the given name
+func (d *Dispatcher) hook(name string) *Hook {
+	for _, h := range d.hooks {
+		if h.Name == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// prune removes the oldest finished deliveries beyond the retained count
+func (d *Dispatcher) prune() {
+	var finished []*Delivery
+	for _, dl := range d.deliveries {
+		if dl.Status != StatusPending {
+			finished = append(finished, dl)
+		}
+	}
+	if len(finished) <= retainFinished {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CreatedAt.After(finished[j].CreatedAt) })
+	for _, dl := range finished[retainFinished:] {
+		delete(d.deliveries, dl.ID)
+		os.Remove(filepath.Join(d.dir, dl.ID+".json"))
+	}
+}
+
+// Deliveries returns the deliveries of the hook, most recent first. The
+// deliveries of every hook are returned if the name is empty.
+func (d *Dispatcher) Deliveries(hook string) []*Delivery {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	deliveries := []*Delivery{}
+	for _, dl := range d.deliveries {
+		if hook == "" || dl.Hook == hook {
+			copied := *dl
+			deliveries = append(deliveries, &copied)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	return deliveries
+}
+
+// Status summarizes the deliveries of every hook
+func (d *Dispatcher) Status() []HookStatus {
+	statuses := []HookStatus{}
+	for _, h := range d.hooks {
+		st := HookStatus{
+			Name:   h.Name,
+			URL:    h.URL,
+			Events: h.Events,
+		}
+
+		for _, dl := range d.Deliveries(h.Name) {
+			switch dl.Status {
+			case StatusPending:
+				st.Pending++
+			case StatusDelivered:
+				st.Delivered++
+			case StatusFailed:
+				st.Failed++
+			}
+			if st.Last == nil {
+				st.Last = dl
+			}
+		}
+
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// Sign returns the signature of the payload keyed by the secret
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the signature is of the payload keyed by the secret
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(strings.TrimSpace(signature)))
+}
+
+// newID returns a random id
+func newID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
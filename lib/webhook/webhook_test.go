@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testDispatcher(t *testing.T, dir string, hooks ...*Hook) *Dispatcher {
+	d, err := NewDispatcher(dir, hooks, log.New(os.Stderr, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	d.Backoff = 10 * time.Millisecond
+	d.MaxBackoff = 50 * time.Millisecond
+	d.MaxAttempts = 3
+	return d
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "webhook")
+	defer os.RemoveAll(dir)
+
+	var lock sync.Mutex
+	var calls int
+	var signature, event string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		calls++
+		// The first attempt fails
+		if calls == 1 {
+			w.WriteHeader(503)
+			return
+		}
+		if !Verify("s3cr3t", body, r.Header.Get(SignatureHeader)) {
+			w.WriteHeader(401)
+			return
+		}
+		signature = r.Header.Get(SignatureHeader)
+		event = r.Header.Get(EventHeader)
+	}))
+	defer srv.Close()
+
+	d := testDispatcher(t, dir,
+		&Hook{Name: "cmdb", URL: srv.URL, Events: []EventType{VolumeCreated}, Secret: "s3cr3t"},
+		&Hook{Name: "chat", URL: srv.URL, Events: []EventType{VolumeDegraded}},
+	)
+	d.Start()
+	defer d.Stop()
+
+	d.Publish(Event{Type: VolumeCreated, Volume: "vol1"})
+
+	waitFor(t, func() bool {
+		dls := d.Deliveries("cmdb")
+		return len(dls) == 1 && dls[0].Status == StatusDelivered
+	})
+
+	lock.Lock()
+	defer lock.Unlock()
+	if calls != 2 || signature == "" || event != string(VolumeCreated) {
+		t.Fatalf("bad: calls: %d, signature: %s, event: %s", calls, signature, event)
+	}
+
+	// The chat hook does not subscribe to volume.created
+	if dls := d.Deliveries("chat"); len(dls) != 0 {
+		t.Fatalf("bad: %+v", dls)
+	}
+
+	st := d.Status()
+	if len(st) != 2 || st[0].Delivered != 1 || st[0].Last.Attempts != 2 {
+		t.Fatalf("bad: %+v", st)
+	}
+}
+
+func TestDispatcherFails(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "webhook")
+	defer os.RemoveAll(dir)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer srv.Close()
+
+	d := testDispatcher(t, dir, &Hook{Name: "cmdb", URL: srv.URL})
+	d.Start()
+	defer d.Stop()
+
+	d.Publish(Event{Type: VolumeDeleted, Volume: "vol1"})
+
+	waitFor(t, func() bool {
+		dls := d.Deliveries("")
+		return len(dls) == 1 && dls[0].Status == StatusFailed
+	})
+
+	if dl := d.Deliveries("")[0]; dl.Attempts != 3 || dl.LastError == "" {
+		t.Fatalf("bad: %+v", dl)
+	}
+}
+
+func TestDispatcherResumes(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "webhook")
+	defer os.RemoveAll(dir)
+
+	// The delivery is queued but never attempted
+	hook := &Hook{Name: "cmdb", URL: "http://127.0.0.1:1"}
+	d := testDispatcher(t, dir, hook)
+	d.Publish(Event{Type: VolumeResized, Volume: "vol1"})
+
+	delivered := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- r.Header.Get(DeliveryHeader)
+	}))
+	defer srv.Close()
+
+	// The pending delivery is resumed from the queue on disk
+	d = testDispatcher(t, dir, &Hook{Name: "cmdb", URL: srv.URL})
+	d.Start()
+	defer d.Stop()
+
+	select {
+	case id := <-delivered:
+		if dls := d.Deliveries("cmdb"); len(dls) != 1 || dls[0].ID != id {
+			t.Fatalf("bad: %+v", dls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out")
+	}
+}
+
+func TestInvalidHook(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "webhook")
+	defer os.RemoveAll(dir)
+
+	hooks := []*Hook{{Name: "cmdb", URL: "http://cmdb", Events: []EventType{"volume.exploded"}}}
+	if _, err := NewDispatcher(dir, hooks, log.New(os.Stderr, "", 0)); err == nil {
+		t.Fatalf("expected an invalid event error")
+	}
+}
+
+func TestDispatcherSlowHook(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "webhook")
+	defer os.RemoveAll(dir)
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	d := testDispatcher(t, dir,
+		&Hook{Name: "slow", URL: slow.URL, Events: []EventType{VolumeCreated}},
+		&Hook{Name: "fast", URL: fast.URL, Events: []EventType{VolumeCreated}},
+	)
+	d.Start()
+
+	d.Publish(Event{Type: VolumeCreated, Volume: "vol1"})
+
+	// The slow hook must not hold up the delivery to the fast one
+	waitFor(t, func() bool {
+		dls := d.Deliveries("fast")
+		return len(dls) == 1 && dls[0].Status == StatusDelivered
+	})
+
+	release <- struct{}{}
+	d.Stop()
+	// Stop is idempotent
+	d.Stop()
+}